@@ -1,9 +1,10 @@
 package chain
 
 import (
-	"bytes"
-	"encoding/gob"
+	"crypto/sha256"
+	"fmt"
 
+	"github.com/lainio/err2"
 	"github.com/lainio/err2/try"
 	"github.com/lainio/ic/crypto"
 )
@@ -12,13 +13,25 @@ type Block struct {
 	HashToPrev        []byte           // check the size later
 	InviteePubKey     crypto.PubKey    // TODO: check the type later?
 	InvitersSignature crypto.Signature // TODO: check the type
-	Position          int
+	Position          Position
+	Kind              Kind
+	Validity          // zero means valid forever
+
+	// AcceptanceSignature is the invitee's optional proof-of-possession of the
+	// InviteePubKey, see Offer.
+	AcceptanceSignature crypto.Signature
+
+	// Algorithm is the signature algorithm of the InviteePubKey. It's signed
+	// by the inviter, i.e. it cannot be changed afterwards.
+	Algorithm crypto.Algorithm
 }
 
 // NewVerifyBlock returns two randomized Blocks that can be used for
 // verification or challenges, etc. First block is for challenge, i.e. pinCode
 // is unknown aka 0, and second block is for actual signing where pincode is set
-// to Position field. By this we can send pincode by other, safe channel.
+// to Position field. By this we can send pincode by other, safe channel. Note
+// that the pinCode can be brute-forced from the signature offline. Use package
+// challenge's PINVerifier over networks.
 func NewVerifyBlock(pinCode int) (Block, Block) {
 	challengeBlock := Block{
 		HashToPrev:    crypto.RandSlice(32),
@@ -27,38 +40,58 @@ func NewVerifyBlock(pinCode int) (Block, Block) {
 	return challengeBlock, Block{
 		HashToPrev:    challengeBlock.HashToPrev,
 		InviteePubKey: challengeBlock.InviteePubKey,
-		Position:      pinCode,
+		Position:      Position(pinCode),
 	}
 }
 
 func NewBlockFromData(d []byte) (b Block) {
-	r := bytes.NewReader(d)
-	dec := gob.NewDecoder(r)
-	try.To(dec.Decode(&b))
-	return b
+	return try.To1(ParseBlock(d))
+}
+
+// ParseBlock is error returning version of NewBlockFromData. It's meant for the
+// data received from untrusted sources. Decoding errors are ErrDecode.
+func ParseBlock(d []byte) (b Block, err error) {
+	defer err2.Handle(&err)
+
+	return decodeBlock(d)
 }
 
 func (b Block) Bytes() []byte {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	try.To(enc.Encode(b))
-	return buf.Bytes()
+	return try.To1(b.TryBytes())
 }
 
+// TryBytes is error returning version of Bytes. The encoding is canonical, see
+// EncodingVersion for the format.
+func (b Block) TryBytes() (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	return encodeBlock(b)
+}
+
+// Hash returns the hash of the whole block including the signature. The next
+// block in the chain stores it to HashToPrev.
+func (b Block) Hash() []byte {
+	ha := sha256.Sum256(b.Bytes())
+	return ha[:]
+}
+
+// ExcludeSign returns a copy of the block without InvitersSignature. Its bytes
+// are the signing input of the block.
 func (b Block) ExcludeSign() Block {
-	newBlock := Block{
-		HashToPrev:    b.HashToPrev,
-		InviteePubKey: b.InviteePubKey,
-		Position:      b.Position,
-	}
-	return newBlock
+	b.InvitersSignature = nil
+	return b
 }
 
 func EqualBlocks(b1, b2 Block) bool {
 	return crypto.EqualBytes(b1.HashToPrev, b2.HashToPrev) &&
 		crypto.EqualBytes(b1.InviteePubKey, b2.InviteePubKey) &&
 		crypto.EqualBytes(b1.InvitersSignature, b2.InvitersSignature) &&
-		b1.Position == b2.Position
+		b1.Position == b2.Position &&
+		b1.Kind == b2.Kind &&
+		b1.NotBefore.Equal(b2.NotBefore) &&
+		b1.NotAfter.Equal(b2.NotAfter) &&
+		crypto.EqualBytes(b1.AcceptanceSignature, b2.AcceptanceSignature) &&
+		b1.Algorithm == b2.Algorithm
 }
 
 func (b Block) VerifySign(invitersPubKey crypto.PubKey) bool {
@@ -68,3 +101,24 @@ func (b Block) VerifySign(invitersPubKey crypto.PubKey) bool {
 		b.InvitersSignature,
 	)
 }
+
+// validAlgorithm tells if the block's Algorithm is the algorithm of its
+// InviteePubKey. Like before the algorithms, the malformed keys are allowed
+// for the default algorithm, they just cannot sign anything.
+func (b Block) validAlgorithm() bool {
+	alg, err := algorithmOf(b.InviteePubKey)
+	if err != nil {
+		return b.Algorithm == crypto.Ed25519
+	}
+	return alg == b.Algorithm
+}
+
+// algorithmOf returns the algorithm of the pubKey. It returns ErrAlgorithm if
+// the pubKey is malformed or of unknown algorithm.
+func algorithmOf(pubKey crypto.PubKey) (crypto.Algorithm, error) {
+	alg, _, err := crypto.ParsePubKey(pubKey)
+	if err != nil {
+		return alg, fmt.Errorf("%w: %v", ErrAlgorithm, err)
+	}
+	return alg, nil
+}
@@ -1,6 +1,7 @@
 package chain
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/lainio/err2/assert"
@@ -15,14 +16,27 @@ func TestNewVerifyBlock(t *testing.T) {
 		args args
 		want int // length of Block
 	}{
-		{"nil pincode", args{0}, 159},
+		{"nil pincode", args{0}, 71},
+		{"pincode", args{1234}, 82},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			defer assert.PushTester(t)()
 
-			cb, _ := NewVerifyBlock(tt.args.pinCode)
-			assert.SLen(cb.Bytes(), tt.want)
+			_, sb := NewVerifyBlock(tt.args.pinCode)
+			assert.SLen(sb.Bytes(), tt.want)
 		})
 	}
 }
+
+func TestParseBlock(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	cb, _ := NewVerifyBlock(0)
+	b, err := ParseBlock(cb.Bytes())
+	assert.NoError(err)
+	assert.That(EqualBlocks(cb, b))
+
+	_, err = ParseBlock([]byte{0x01, 0x02})
+	assert.That(errors.Is(err, ErrDecode))
+}
@@ -4,11 +4,9 @@
 package chain
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"encoding/gob"
+	"fmt"
 
-	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2"
 	"github.com/lainio/err2/try"
 	"github.com/lainio/ic/crypto"
 )
@@ -51,31 +49,34 @@ func SameRoot(c1, c2 Chain) bool {
 }
 
 func SameInviter(c1, c2 Chain) bool {
-	if !c1.Verify() || !c2.Verify() {
+	if c1.Depth() < 1 || c2.Depth() < 1 || !c1.Verify() || !c2.Verify() {
 		return false
 	}
 	return EqualBlocks(
-		c1.secondLastBlock(),
-		c2.secondLastBlock(),
+		c1.secondLastMember(),
+		c2.secondLastMember(),
 	)
 }
 
 // CommonInviter returns inviter's distance (current level) from chain's root if
-// inviter exists.  If not it returns NotConnected
+// inviter exists.  If not it returns NotConnected. Key rotations don't change
+// the levels, see Rotate.
 func CommonInviter(c1, c2 Chain) (level int) {
 	if !SameRoot(c1, c2) {
 		return NotConnected
 	}
 
+	m1, m2 := c1.members(), c2.members()
+
 	// pickup the shorter of the chains for the compare loop below
-	c := c1
-	if c1.Len() > c2.Len() {
-		c = c2
+	m := m1
+	if len(m1) > len(m2) {
+		m = m2
 	}
 
 	// root is the same, start from next until difference is found
-	for i := range c.Blocks[1:] {
-		if !EqualBlocks(c1.Blocks[i], c2.Blocks[i]) {
+	for i := range m[1:] {
+		if !EqualBlocks(c1.Blocks[m1[i]], c2.Blocks[m2[i]]) {
 			return i - 1
 		}
 		level = i
@@ -89,19 +90,32 @@ func Hops(lhs, rhs Chain) (int, int) {
 
 func NewRootChain(rootPubKey crypto.PubKey) Chain {
 	chain := Chain{Blocks: make([]Block, 1, 12)}
+	alg, _ := algorithmOf(rootPubKey)
 	chain.Blocks[0] = Block{
 		HashToPrev:        nil,
 		InviteePubKey:     rootPubKey,
 		InvitersSignature: nil,
+		Algorithm:         alg,
 	}
 	return chain
 }
 
 func NewChainFromData(d []byte) (c Chain) {
-	r := bytes.NewReader(d)
-	dec := gob.NewDecoder(r)
-	try.To(dec.Decode(&c))
-	return c
+	return try.To1(decodeChain(d))
+}
+
+// ParseChain is error returning version of NewChainFromData. It's meant for the
+// data received from untrusted sources, i.e. it never panics. Decoding errors
+// are ErrDecode and chains without blocks are ErrEmptyChain. Note that the
+// chain isn't verified, call Verify or TryVerify for that.
+func ParseChain(d []byte) (c Chain, err error) {
+	defer err2.Handle(&err)
+
+	c = try.To1(decodeChain(d))
+	if c.Len() == 0 {
+		return Nil, ErrEmptyChain
+	}
+	return c, nil
 }
 
 func (c Chain) IsNil() bool {
@@ -109,32 +123,74 @@ func (c Chain) IsNil() bool {
 }
 
 func (c Chain) Bytes() []byte {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	try.To(enc.Encode(c))
-	return buf.Bytes()
+	return try.To1(c.TryBytes())
+}
+
+// TryBytes is error returning version of Bytes.
+func (c Chain) TryBytes() (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	return encodeChain(c)
 }
 
 // Invite is called for the inviter's chain. Inviter's key is needed for signing
 // the new link/block which includes inviteesPubKey and position in the chain.
 // A new chain is returned. The chain will be given for the invitee.
 func (c Chain) Invite(
-	invitersKey crypto.Key,
+	invitersKey crypto.KeyHandle,
 	inviteesPubKey crypto.PubKey,
-	position int,
+	position Position,
 ) (nc Chain) {
-	assert.That(c.isLeaf(invitersKey), "only leaf can invite")
+	return try.To1(c.TryInvite(invitersKey, inviteesPubKey, position))
+}
 
-	newBlock := Block{
-		HashToPrev:    c.hashToLeaf(),
+// TryInvite is error returning version of Invite. It returns ErrEmptyChain if
+// the chain has no blocks, ErrNotLeaf if invitersKey isn't the leaf key of the
+// chain, ErrPosition if the position is better than the inviter's, see
+// Position, and ErrAlgorithm if the inviteesPubKey is malformed.
+func (c Chain) TryInvite(
+	invitersKey crypto.KeyHandle,
+	inviteesPubKey crypto.PubKey,
+	position Position,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	return c.addBlock(invitersKey, Block{
 		InviteePubKey: inviteesPubKey,
 		Position:      position,
+	})
+}
+
+// addBlock links the newBlock to the leaf, signs it with the leaf key, and
+// returns a new chain where the block is added. The block's Algorithm is set
+// from its InviteePubKey. It returns ErrEmptyChain if the chain has no blocks,
+// ErrNotLeaf if leafKey isn't the leaf key of the chain, ErrPosition if the
+// leaf cannot give the newBlock's position, and ErrAlgorithm if the
+// InviteePubKey is malformed.
+func (c Chain) addBlock(
+	leafKey crypto.KeyHandle,
+	newBlock Block,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	if c.Len() == 0 {
+		return Nil, ErrEmptyChain
+	}
+	if !c.isLeaf(leafKey) {
+		return Nil, ErrNotLeaf
 	}
-	newBlock.InvitersSignature = invitersKey.Sign(newBlock.Bytes())
+	if !c.Position().CanInvite(newBlock.Position) {
+		return Nil, fmt.Errorf("%w: %v cannot invite %v",
+			ErrPosition, c.Position(), newBlock.Position)
+	}
+
+	newBlock.Algorithm = try.To1(algorithmOf(newBlock.InviteePubKey))
+	newBlock.HashToPrev = c.hashToLeaf()
+	newBlock.InvitersSignature = leafKey.Sign(try.To1(newBlock.TryBytes()))
 
-	nc = c.Clone()
+	nc = try.To1(c.TryClone())
 	nc.Blocks = append(nc.Blocks, newBlock)
-	return nc
+	return nc, nil
 }
 
 // Hops returns hops and common inviter's level if that exists. If not both
@@ -149,8 +205,9 @@ func (c Chain) Hops(their Chain) (int, int) {
 		return 1, common
 	}
 
-	// both chain lengths without self, minus "tail" to common inviter
-	hops := c.Len() - 1 + their.Len() - 1 - 2*common
+	// both chain depths, i.e. lengths without self, minus "tail" to common
+	// inviter
+	hops := c.Depth() + their.Depth() - 2*common
 
 	return hops, common
 }
@@ -164,69 +221,115 @@ func (c Chain) Len() int {
 	return len(c.Blocks)
 }
 
-func (c Chain) isLeaf(invitersKey crypto.Key) bool {
-	return invitersKey.PubKeyEqual(c.LeafPubKey())
+func (c Chain) isLeaf(invitersKey crypto.KeyHandle) bool {
+	return crypto.EqualBytes(invitersKey.PublicKey(), c.LeafPubKey())
 }
 
 func (c Chain) LeafPubKey() crypto.PubKey {
-	assert.That(c.Len() > 0, "chain cannot be empty")
+	return try.To1(c.TryLeafPubKey())
+}
 
-	return c.lastBlock().InviteePubKey
+// TryLeafPubKey is error returning version of LeafPubKey. It returns
+// ErrEmptyChain if the chain has no blocks.
+func (c Chain) TryLeafPubKey() (crypto.PubKey, error) {
+	if c.Len() == 0 {
+		return nil, ErrEmptyChain
+	}
+	return c.lastBlock().InviteePubKey, nil
+}
+
+// InviterPubKey returns the key which invited the leaf member, i.e. signed its
+// invitation block. The leaf member's key rotations are skipped. The root chain
+// has no inviter and nil is returned.
+func (c Chain) InviterPubKey() crypto.PubKey {
+	return try.To1(c.TryInviterPubKey())
+}
+
+// TryInviterPubKey is error returning version of InviterPubKey. It returns
+// ErrEmptyChain if the chain has no blocks.
+func (c Chain) TryInviterPubKey() (crypto.PubKey, error) {
+	if c.Len() == 0 {
+		return nil, ErrEmptyChain
+	}
+	m := c.members()
+	leaf := m[len(m)-1]
+	if leaf == 0 {
+		return nil, nil
+	}
+	return c.Blocks[leaf-1].InviteePubKey, nil
 }
 
 func (c Chain) hashToLeaf() []byte {
 	if c.Blocks == nil {
 		return nil
 	}
-	ha := sha256.Sum256(c.lastBlock().Bytes())
-	return ha[:]
+	return c.lastBlock().Hash()
 }
 
 func (c Chain) Verify() bool {
-	if c.Len() == 1 {
-		return true // root block is valid always
-	}
-
-	var invitersPubKey crypto.PubKey
-	// start with the root key
-	invitersPubKey = c.firstBlock().InviteePubKey
+	return c.VerifyReport().OK()
+}
 
-	for _, b := range c.Blocks[1:] {
-		if !b.VerifySign(invitersPubKey) {
-			return false
-		}
-		// the next block is signed with this block's pub key
-		invitersPubKey = b.InviteePubKey
-	}
-	return true
+// TryVerify is error returning version of Verify. The returned error is one of
+// the sentinel errors: ErrEmptyChain, ErrBadRoot, ErrHashLink, or
+// ErrBadSignature, wrapped with the index of the failing block. Use
+// VerifyReport if you need the details as data.
+func (c Chain) TryVerify() error {
+	return c.VerifyReport().Err()
 }
 
 func (c Chain) Clone() Chain {
-	return NewChainFromData(c.Bytes())
+	return try.To1(c.TryClone())
+}
+
+// TryClone is error returning version of Clone.
+func (c Chain) TryClone() (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	return decodeChain(try.To1(c.TryBytes()))
 }
 
 func (c Chain) IsInviterFor(invitee Chain) bool {
-	if !invitee.Verify() {
+	if c.Len() == 0 || invitee.Depth() < 1 || !invitee.Verify() {
 		return false
 	}
 
 	return EqualBlocks(
-		c.lastBlock(),
-		invitee.secondLastBlock(),
+		c.lastMember(),
+		invitee.secondLastMember(),
 	)
 }
 
 // Challenge offers a method and placeholder for challenging other chain holder.
 // Most common cases is that caller of the function implements the closure where
 // it calls other party over the network to sign the challenge which is readily
-// build and randomized.
+// build and randomized. For the network use there is package challenge which
+// binds challenges to the verifier and protects against replays.
 func (c Chain) Challenge(pinCode int, f func(d []byte) crypto.Signature) bool {
+	if c.Len() == 0 {
+		return false
+	}
 	pubKey := c.lastBlock().InviteePubKey
 	challengeBlock, sigBlock := NewVerifyBlock(pinCode)
 	sig := f(challengeBlock.Bytes())
 	return crypto.VerifySign(pubKey, sigBlock.Bytes(), sig)
 }
 
+// SignChallenge is the responder side of the Challenge. The challenged chain
+// holder signs the received challenge block d with its key handle after the
+// pinCode, received from the other, safe channel, is set to it.
+func SignChallenge(
+	h crypto.KeyHandle,
+	d []byte,
+	pinCode int,
+) (sig crypto.Signature, err error) {
+	defer err2.Handle(&err)
+
+	b := try.To1(ParseBlock(d))
+	b.Position = Position(pinCode)
+	return h.Sign(try.To1(b.TryBytes())), nil
+}
+
 func (c Chain) firstBlock() Block {
 	return c.Blocks[0]
 }
@@ -235,6 +338,16 @@ func (c Chain) lastBlock() Block {
 	return c.Blocks[len(c.Blocks)-1]
 }
 
-func (c Chain) secondLastBlock() Block {
-	return c.Blocks[len(c.Blocks)-2]
+// lastMember returns the block where the leaf member was invited, i.e. its
+// possible key rotations are skipped.
+func (c Chain) lastMember() Block {
+	m := c.members()
+	return c.Blocks[m[len(m)-1]]
+}
+
+// secondLastMember returns the block where the inviter of the leaf member was
+// invited.
+func (c Chain) secondLastMember() Block {
+	m := c.members()
+	return c.Blocks[m[len(m)-2]]
 }
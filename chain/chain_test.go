@@ -1,10 +1,12 @@
 package chain
 
 import (
+	"errors"
 	"os"
 	"testing"
 
 	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
 	"github.com/lainio/ic/crypto"
 )
 
@@ -37,7 +39,7 @@ func setup() {
 	rootKey = crypto.NewKey()
 	testChain = NewRootChain(rootKey.PubKey)
 	inviteeKey = crypto.NewKey()
-	level := 1
+	var level Position = 1
 	testChain = testChain.Invite(rootKey, inviteeKey.PubKey, level)
 
 	// root, alice, bob setup
@@ -88,7 +90,7 @@ func TestVerifyChain(t *testing.T) {
 	assert.That(testChain.Verify())
 
 	newInvitee := crypto.NewKey()
-	level := 3
+	var level Position = 3
 	testChain = testChain.Invite(inviteeKey, newInvitee.PubKey, level)
 
 	assert.SLen(testChain.Blocks, 3)
@@ -228,7 +230,7 @@ func TestChallengeInvitee(t *testing.T) {
 			// In real world usage here we would send the d for Alice's signing
 			// over the network.
 			b := NewBlockFromData(d)
-			b.Position = pinCode
+			b.Position = Position(pinCode)
 			d = b.Bytes()
 			return alice.Sign(d)
 		},
@@ -236,7 +238,7 @@ func TestChallengeInvitee(t *testing.T) {
 	assert.That(bob.Challenge(pinCode,
 		func(d []byte) crypto.Signature {
 			b := NewBlockFromData(d)
-			b.Position = pinCode
+			b.Position = Position(pinCode)
 			d = b.Bytes()
 			return bob.Sign(d)
 		},
@@ -245,7 +247,7 @@ func TestChallengeInvitee(t *testing.T) {
 	assert.ThatNot(bob.Challenge(pinCode,
 		func(d []byte) crypto.Signature {
 			b := NewBlockFromData(d)
-			b.Position = pinCode
+			b.Position = Position(pinCode)
 			d = b.Bytes()
 			// NOTE Alice canot sign bob's challenge
 			return alice.Sign(d)
@@ -255,7 +257,7 @@ func TestChallengeInvitee(t *testing.T) {
 	assert.ThatNot(bob.Challenge(pinCode+1,
 		func(d []byte) crypto.Signature {
 			b := NewBlockFromData(d)
-			b.Position = pinCode
+			b.Position = Position(pinCode)
 			d = b.Bytes()
 			return bob.Sign(d)
 		},
@@ -274,3 +276,130 @@ func TestChallengeInvitee(t *testing.T) {
 
 // Other poptential problem is key rotation. It isn't so big problem when we
 // have a network in the came. Invitation Chain IDs aren
+
+func TestParseChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c, err := ParseChain(testChain.Bytes())
+	assert.NoError(err)
+	assert.That(c.Verify())
+	assert.SLen(c.Blocks, testChain.Len())
+
+	_, err = ParseChain(nil)
+	assert.That(errors.Is(err, ErrDecode))
+
+	_, err = ParseChain([]byte("not a gob stream"))
+	assert.That(errors.Is(err, ErrDecode))
+
+	_, err = ParseChain(Nil.Bytes())
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestTryInvite(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c, err := alice.TryInvite(alice.Key, crypto.NewKey().PubKey, 1)
+	assert.NoError(err)
+	assert.SLen(c.Blocks, 3)
+	assert.That(c.Verify())
+
+	_, err = alice.TryInvite(bob.Key, crypto.NewKey().PubKey, 1)
+	assert.That(errors.Is(err, ErrNotLeaf))
+
+	_, err = Nil.TryInvite(bob.Key, crypto.NewKey().PubKey, 1)
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestTryLeafPubKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	pubKey, err := alice.TryLeafPubKey()
+	assert.NoError(err)
+	assert.DeepEqual(pubKey, alice.PubKey)
+
+	_, err = Nil.TryLeafPubKey()
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestInviterPubKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	assert.DeepEqual(alice.InviterPubKey(), root.PubKey)
+	assert.Equal(len(root.InviterPubKey()), 0)
+
+	// rotations of the leaf are skipped, the inviter's aren't
+	aliceKey2 := crypto.NewKey()
+	alice2 := alice.Rotate(alice.Key, aliceKey2.PubKey)
+	assert.DeepEqual(alice2.InviterPubKey(), root.PubKey)
+	carol := alice2.Invite(aliceKey2, crypto.NewKey().PubKey, 1)
+	assert.DeepEqual(carol.InviterPubKey(), aliceKey2.PubKey)
+
+	_, err := Nil.TryInviterPubKey()
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestTryVerify(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	assert.NoError(alice.TryVerify())
+	assert.That(errors.Is(Nil.TryVerify(), ErrEmptyChain))
+
+	c := alice.Clone()
+	c.Blocks[1].InvitersSignature[0] += 0x01
+	assert.That(errors.Is(c.TryVerify(), ErrBadSignature))
+}
+
+// TestMalformedData tests that corrupted chain data from untrusted peers cannot
+// crash the process, i.e. every byte mutation and truncation either fails to
+// parse or gives a chain that can be safely used.
+func TestMalformedData(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	d := alice.Bytes()
+	for i := range d {
+		mutated := append([]byte{}, d...)
+		mutated[i] ^= 0xff
+		useUntrusted(mutated)
+		useUntrusted(d[:i])
+	}
+}
+
+func FuzzParseChain(f *testing.F) {
+	f.Add(alice.Bytes())
+	f.Add(testChain.Bytes())
+	f.Add(Nil.Bytes())
+	f.Add([]byte{})
+	f.Fuzz(func(_ *testing.T, d []byte) {
+		useUntrusted(d)
+	})
+}
+
+func useUntrusted(d []byte) {
+	c, err := ParseChain(d)
+	if err != nil {
+		return
+	}
+	_ = c.TryVerify()
+	_, _ = c.TryLeafPubKey()
+	_, _ = c.TryInvite(alice.Key, bob.PubKey, 1)
+	_, _ = c.Hops(alice.Chain)
+	_ = SameInviter(c, alice.Chain)
+	_ = c.IsInviterFor(alice.Chain)
+	_ = alice.IsInviterFor(c)
+}
+
+func TestSignChallenge(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	pinCode := 1234
+	h := alice.Key.Handle()
+	assert.That(alice.Challenge(pinCode, func(d []byte) crypto.Signature {
+		return try.To1(SignChallenge(h, d, pinCode))
+	}))
+	assert.ThatNot(bob.Challenge(pinCode, func(d []byte) crypto.Signature {
+		return try.To1(SignChallenge(h, d, pinCode))
+	}))
+
+	_, err := SignChallenge(h, []byte{0x00}, pinCode)
+	assert.That(errors.Is(err, ErrDecode))
+}
@@ -0,0 +1,304 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+const (
+	// attestationDomain separates the root's attestation signatures from the
+	// other signatures.
+	attestationDomain = "ic-attestation-v1"
+
+	// presentationDomain separates the holder's presentation signatures from
+	// the other signatures.
+	presentationDomain = "ic-presentation-v1"
+
+	// commitmentDomain separates the chain commitments from the other hashes.
+	commitmentDomain = "ic-commitment-v1"
+
+	saltSize = 32
+)
+
+// Attestation is the selective disclosure of a chain. A full chain exposes the
+// keys of every ancestor of the holder, but the signatures of the chain cannot
+// be verified without them. That's why the disclosure is two-phase:
+//
+//  1. The holder sends the full chain privately to its root, which verifies it
+//     and signs an attestation with Attest. The attestation reveals only the
+//     root, the leaf key, the position and an upper bound of the depth. The
+//     rest of the chain is hidden behind a salted hash commitment, which the
+//     holder can open later with the Opening, e.g. in a dispute.
+//  2. The holder presents the attestation to verifiers with Present, which
+//     binds it to the verifier's nonce with the leaf key. The verifier checks
+//     the presentation with Presentation.Verify, which proves that the holder
+//     is within MaxDepth levels of the root without disclosing its inviters.
+//
+// The verifiers cannot check the revocations of the hidden blocks, which is
+// why the attestations must expire. Attest a pseudonym chain, see
+// Chain.Pseudonym, for every verifier to have different leaf keys for
+// different verifiers. Presenting the same attestation to many verifiers
+// links the presentations.
+type Attestation struct {
+	Root       crypto.PubKey
+	Leaf       crypto.PubKey
+	MaxDepth   int
+	Position   Position
+	Commitment []byte
+	Validity
+	Signature crypto.Signature
+}
+
+// Opening is the holder's secret which opens the Attestation's Commitment.
+type Opening struct {
+	Salt  []byte
+	Chain Chain
+}
+
+// Presentation is the Attestation bound to the verifier's Nonce by the holder.
+type Presentation struct {
+	Attestation Attestation
+	Nonce       []byte
+	Signature   crypto.Signature
+}
+
+// Attest is called by the root of the chain c. It verifies the chain and
+// returns the attestation of it to the holder with the opening of the
+// commitment. The maxDepth is the disclosed bound of the depth, it cannot be
+// less than the chain's Depth. The validity must have NotAfter.
+func Attest(
+	rootKey crypto.KeyHandle,
+	c Chain,
+	maxDepth int,
+	validity Validity,
+) (Attestation, Opening) {
+	return try.To2(TryAttest(rootKey, c, maxDepth, validity))
+}
+
+// TryAttest is error returning version of Attest. It returns ErrEmptyChain if
+// the chain has no blocks, the verification errors of the chain, see
+// TryVerify, ErrBadRoot if the rootKey isn't the root of the chain, and
+// ErrDisclosure for the bad maxDepth and validity. Note that it doesn't check
+// the revocations, call VerifyWith before it.
+func TryAttest(
+	rootKey crypto.KeyHandle,
+	c Chain,
+	maxDepth int,
+	validity Validity,
+) (a Attestation, o Opening, err error) {
+	defer err2.Handle(&err)
+
+	try.To(c.TryVerify())
+	if !crypto.EqualBytes(c.firstBlock().InviteePubKey, rootKey.PublicKey()) {
+		return a, o, fmt.Errorf("%w: key isn't the root of the chain", ErrBadRoot)
+	}
+	if maxDepth < c.Depth() {
+		return a, o, fmt.Errorf("%w: max depth %d is less than depth %d",
+			ErrDisclosure, maxDepth, c.Depth())
+	}
+	if validity.NotAfter.IsZero() {
+		return a, o, fmt.Errorf("%w: attestation must expire", ErrDisclosure)
+	}
+	o = Opening{Salt: crypto.RandSlice(saltSize), Chain: try.To1(c.TryClone())}
+	a = Attestation{
+		Root:       rootKey.PublicKey(),
+		Leaf:       c.LeafPubKey(),
+		MaxDepth:   maxDepth,
+		Position:   c.Position(),
+		Commitment: try.To1(o.commitment()),
+		Validity:   validity.truncate(),
+	}
+	a.Signature = rootKey.Sign(try.To1(a.signingInput()))
+	return a, o, nil
+}
+
+// VerifySign verifies the root's signature of the attestation.
+func (a Attestation) VerifySign() bool {
+	d, err := a.signingInput()
+	return err == nil && crypto.VerifySign(a.Root, d, a.Signature)
+}
+
+// Open checks that the opening matches the attestation, i.e. the committed
+// chain verifies and has the attested root, leaf, position and depth. It
+// returns ErrDisclosure if they don't match.
+func (a Attestation) Open(o Opening) (err error) {
+	defer err2.Handle(&err)
+
+	if !crypto.EqualBytes(try.To1(o.commitment()), a.Commitment) {
+		return fmt.Errorf("%w: commitment doesn't match", ErrDisclosure)
+	}
+	c := o.Chain
+	try.To(c.TryVerify())
+	if !crypto.EqualBytes(c.firstBlock().InviteePubKey, a.Root) ||
+		!crypto.EqualBytes(c.LeafPubKey(), a.Leaf) ||
+		c.Position() != a.Position || c.Depth() > a.MaxDepth {
+		return fmt.Errorf("%w: chain doesn't match", ErrDisclosure)
+	}
+	return nil
+}
+
+// Present is called by the holder to present the attestation to the verifier
+// which gave the nonce. The leafKey must be the attested leaf key.
+func (a Attestation) Present(leafKey crypto.KeyHandle, nonce []byte) Presentation {
+	return try.To1(a.TryPresent(leafKey, nonce))
+}
+
+// TryPresent is error returning version of Present. It returns ErrNotLeaf if
+// the leafKey isn't the attested leaf key.
+func (a Attestation) TryPresent(
+	leafKey crypto.KeyHandle,
+	nonce []byte,
+) (p Presentation, err error) {
+	defer err2.Handle(&err)
+
+	if !crypto.EqualBytes(leafKey.PublicKey(), a.Leaf) {
+		return p, ErrNotLeaf
+	}
+	p = Presentation{Attestation: a, Nonce: nonce}
+	p.Signature = leafKey.Sign(try.To1(p.signingInput()))
+	return p, nil
+}
+
+// Verify is called by the verifier. It checks that the presentation proves
+// that the holder is within maxDepth levels of the root at the time at, and
+// that it's made for the verifier's nonce. The errors are ErrDisclosure,
+// ErrBadSignature and ErrExpired.
+func (p Presentation) Verify(
+	root crypto.PubKey,
+	maxDepth int,
+	nonce []byte,
+	at time.Time,
+) error {
+	a := p.Attestation
+	switch {
+	case !crypto.EqualBytes(a.Root, root):
+		return fmt.Errorf("%w: other root", ErrDisclosure)
+	case !a.VerifySign():
+		return fmt.Errorf("%w: attestation", ErrBadSignature)
+	case !a.ValidAt(at):
+		return fmt.Errorf("%w: attestation", ErrExpired)
+	case a.MaxDepth > maxDepth:
+		return fmt.Errorf("%w: depth %d exceeds %d",
+			ErrDisclosure, a.MaxDepth, maxDepth)
+	case len(nonce) == 0 || !crypto.EqualBytes(p.Nonce, nonce):
+		return fmt.Errorf("%w: nonce doesn't match", ErrDisclosure)
+	}
+	d, err := p.signingInput()
+	if err != nil {
+		return err
+	}
+	if !crypto.VerifySign(a.Leaf, d, p.Signature) {
+		return fmt.Errorf("%w: presentation", ErrBadSignature)
+	}
+	return nil
+}
+
+// ParsePresentation decodes the presentation. Decoding errors are ErrDecode.
+// Note that the presentation isn't verified, call Presentation.Verify for
+// that.
+func ParsePresentation(d []byte) (p Presentation, err error) {
+	defer err2.Handle(&err)
+
+	dec := newDecoder(d)
+	for dec.more() {
+		tag, v := dec.field()
+		switch tag {
+		case 0: // decoding error is already set
+		case tagPresentedAttestation:
+			p.Attestation = try.To1(decodeAttestation(v))
+		case tagPresentationNonce:
+			p.Nonce = clone(v)
+		case tagPresentationSignature:
+			p.Signature = clone(v)
+		default:
+			dec.fail("unknown tag %d", tag)
+		}
+	}
+	return p, dec.result()
+}
+
+func (p Presentation) Bytes() []byte {
+	return try.To1(p.TryBytes())
+}
+
+// TryBytes is error returning version of Bytes.
+func (p Presentation) TryBytes() (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	enc := newEncoder()
+	enc.bytes(tagPresentedAttestation, try.To1(encodeAttestation(p.Attestation)))
+	enc.bytes(tagPresentationNonce, p.Nonce)
+	enc.bytes(tagPresentationSignature, p.Signature)
+	return enc.result()
+}
+
+func (p Presentation) signingInput() ([]byte, error) {
+	p.Signature = nil
+	d, err := p.TryBytes()
+	return append([]byte(presentationDomain), d...), err
+}
+
+func (a Attestation) signingInput() ([]byte, error) {
+	a.Signature = nil
+	d, err := encodeAttestation(a)
+	return append([]byte(attestationDomain), d...), err
+}
+
+func (o Opening) commitment() ([]byte, error) {
+	d, err := o.Chain.TryBytes()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte(commitmentDomain))
+	h.Write(o.Salt)
+	h.Write(d)
+	return h.Sum(nil), nil
+}
+
+func encodeAttestation(a Attestation) ([]byte, error) {
+	enc := newEncoder()
+	enc.bytes(tagAttestedRoot, a.Root)
+	enc.bytes(tagAttestedLeaf, a.Leaf)
+	enc.int(tagAttestedMaxDepth, a.MaxDepth)
+	enc.int(tagAttestedPosition, int(a.Position))
+	enc.bytes(tagAttestedCommitment, a.Commitment)
+	enc.int(tagAttestedNotBefore, toUnix(a.NotBefore))
+	enc.int(tagAttestedNotAfter, toUnix(a.NotAfter))
+	enc.bytes(tagAttestationSignature, a.Signature)
+	return enc.result()
+}
+
+func decodeAttestation(d []byte) (a Attestation, err error) {
+	dec := newDecoder(d)
+	for dec.more() {
+		tag, v := dec.field()
+		switch tag {
+		case 0: // decoding error is already set
+		case tagAttestedRoot:
+			a.Root = clone(v)
+		case tagAttestedLeaf:
+			a.Leaf = clone(v)
+		case tagAttestedMaxDepth:
+			a.MaxDepth = dec.int(v)
+		case tagAttestedPosition:
+			a.Position = Position(dec.int(v))
+		case tagAttestedCommitment:
+			a.Commitment = clone(v)
+		case tagAttestedNotBefore:
+			a.NotBefore = fromUnix(dec.int(v))
+		case tagAttestedNotAfter:
+			a.NotAfter = fromUnix(dec.int(v))
+		case tagAttestationSignature:
+			a.Signature = clone(v)
+		default:
+			dec.fail("unknown tag %d", tag)
+		}
+	}
+	return a, dec.result()
+}
@@ -0,0 +1,164 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestDisclosure(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+
+	a, o := Attest(root.Key, carol, 3, ValidFor(time.Hour))
+	assert.That(a.VerifySign())
+	assert.DeepEqual(a.Root, root.PubKey)
+	assert.DeepEqual(a.Leaf, carolKey.PubKey)
+	assert.Equal(a.MaxDepth, 3)
+	assert.Equal(a.Position, carol.Position())
+	try.To(a.Open(o))
+
+	// verifier sees only the root and the leaf
+	nonce := crypto.RandSlice(32)
+	p := a.Present(carolKey, nonce)
+	d := p.Bytes()
+	assert.That(!containsKey(d, bob.PubKey))
+	p = try.To1(ParsePresentation(d))
+	now := time.Now()
+	try.To(p.Verify(root.PubKey, 3, nonce, now))
+	try.To(p.Verify(root.PubKey, 5, nonce, now))
+
+	err := p.Verify(root.PubKey, 2, nonce, now)
+	assert.That(errors.Is(err, ErrDisclosure))
+	err = p.Verify(alice.PubKey, 3, nonce, now)
+	assert.That(errors.Is(err, ErrDisclosure))
+	err = p.Verify(root.PubKey, 3, crypto.RandSlice(32), now)
+	assert.That(errors.Is(err, ErrDisclosure), "replay to other verifier")
+	err = p.Verify(root.PubKey, 3, nonce, now.Add(2*time.Hour))
+	assert.That(errors.Is(err, ErrExpired))
+}
+
+func TestAttestFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	validity := ValidFor(time.Hour)
+
+	_, _, err := TryAttest(bob.Key, carol, 3, validity)
+	assert.That(errors.Is(err, ErrBadRoot))
+	_, _, err = TryAttest(root.Key, carol, 1, validity)
+	assert.That(errors.Is(err, ErrDisclosure))
+	_, _, err = TryAttest(root.Key, carol, 2, Validity{})
+	assert.That(errors.Is(err, ErrDisclosure))
+	_, _, err = TryAttest(root.Key, Nil, 2, validity)
+	assert.That(errors.Is(err, ErrEmptyChain))
+
+	broken := carol.Clone()
+	broken.Blocks[2].InvitersSignature[0] ^= 0x01
+	_, _, err = TryAttest(root.Key, broken, 2, validity)
+	assert.That(errors.Is(err, ErrBadSignature))
+}
+
+func TestPresentationForged(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	a, _ := Attest(root.Key, carol, 2, ValidFor(time.Hour))
+	nonce := crypto.RandSlice(32)
+	now := time.Now()
+
+	// only the leaf key can present
+	_, err := a.TryPresent(bob.Key, nonce)
+	assert.That(errors.Is(err, ErrNotLeaf))
+
+	// holder cannot change the attested depth
+	p := a.Present(carolKey, nonce)
+	p.Attestation.MaxDepth = 1
+	err = p.Verify(root.PubKey, 1, nonce, now)
+	assert.That(errors.Is(err, ErrBadSignature))
+
+	// other key cannot use the attestation
+	malloryKey := crypto.NewKey()
+	p = a.Present(carolKey, nonce)
+	p.Signature = malloryKey.Sign(try.To1(p.signingInput()))
+	err = p.Verify(root.PubKey, 2, nonce, now)
+	assert.That(errors.Is(err, ErrBadSignature))
+
+	// self-made attestation isn't from the root
+	mallory := NewRootChain(malloryKey.PubKey).
+		Invite(malloryKey, carolKey.PubKey, 1)
+	forged, _ := Attest(malloryKey, mallory, 1, ValidFor(time.Hour))
+	forged.Root = root.PubKey
+	err = forged.Present(carolKey, nonce).Verify(root.PubKey, 2, nonce, now)
+	assert.That(errors.Is(err, ErrBadSignature))
+}
+
+func TestOpening(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	a, o := Attest(root.Key, carol, 2, ValidFor(time.Hour))
+
+	// commitment is salted, i.e. same chain gives other commitments
+	a2, _ := Attest(root.Key, carol, 2, ValidFor(time.Hour))
+	assert.That(!crypto.EqualBytes(a.Commitment, a2.Commitment))
+
+	other := Opening{Salt: o.Salt, Chain: alice.Invite(alice.Key, carolKey.PubKey, 1)}
+	assert.That(errors.Is(a.Open(other), ErrDisclosure))
+	other = Opening{Salt: crypto.RandSlice(saltSize), Chain: o.Chain}
+	assert.That(errors.Is(a.Open(other), ErrDisclosure))
+}
+
+func TestDisclosePseudonym(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	pseudoKey := PseudonymKey(carolKey, []byte("verifier"))
+	pseudo := carol.Pseudonym(carolKey, pseudoKey.PubKey)
+
+	a, o := Attest(root.Key, pseudo, 2, ValidFor(time.Hour))
+	try.To(a.Open(o))
+	nonce := crypto.RandSlice(32)
+	p := a.Present(pseudoKey, nonce)
+	try.To(p.Verify(root.PubKey, 2, nonce, time.Now()))
+	assert.That(!containsKey(p.Bytes(), carolKey.PubKey))
+}
+
+func TestParsePresentationFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	a, _ := Attest(root.Key, carol, 2, ValidFor(time.Hour))
+	d := a.Present(carolKey, crypto.RandSlice(32)).Bytes()
+
+	_, err := ParsePresentation(d[:len(d)-1])
+	assert.That(errors.Is(err, ErrDecode))
+	_, err = ParsePresentation(append(d, 0))
+	assert.That(errors.Is(err, ErrDecode))
+
+	// attestation is decoded strictly as well
+	bad := append([]byte(nil), d...)
+	bad[4] ^= 0xff
+	_, err = ParsePresentation(bad)
+	assert.That(errors.Is(err, ErrDecode))
+}
+
+func containsKey(d []byte, key crypto.PubKey) bool {
+	for i := 0; i+len(key) <= len(d); i++ {
+		if crypto.EqualBytes(d[i:i+len(key)], key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,415 @@
+package chain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/lainio/ic/crypto"
+)
+
+// Wire encoding
+//
+// Blocks and chains use a hand-specified, deterministic binary encoding. The
+// same bytes are used as the signing input and for the transport, which is why
+// there must be exactly one encoding for every value. The format is simple
+// enough to be implemented in any language, see testdata/vectors.json for the
+// golden test vectors.
+//
+// All integers are big-endian. A block is:
+//
+//	version  1 byte, EncodingVersion
+//	fields   0..n TLV fields in strictly ascending tag order
+//
+// Each field is:
+//
+//	tag      1 byte
+//	length   2 bytes, uint16, length of the value, > 0
+//	value    length bytes
+//
+// Fields with zero value (empty byte slice or zero integer) are omitted. The
+// tags of version 1 are:
+//
+//	0x01 HashToPrev           bytes
+//	0x02 InviteePubKey        bytes
+//	0x03 InvitersSignature    bytes
+//	0x04 Position             int64, two's complement, 8 bytes
+//	0x05 Kind                 uint8, 1 byte, 0 invite (omitted), 1 rotation,
+//	                          2 pseudonym
+//	0x06 NotBefore            int64, Unix time in seconds, 8 bytes
+//	0x07 NotAfter             int64, Unix time in seconds, 8 bytes
+//	0x08 AcceptanceSignature  bytes, optional, see Offer
+//	0x09 Algorithm            uint8, 1 byte, 0 ed25519 (omitted), 1 p256, see
+//	                          crypto.Algorithm
+//
+// The signing input of the block is the encoding of the block without the
+// InvitersSignature field, the invitee's AcceptanceSignature is over
+// "ic-accept-v1" || the encoding without both of the signatures, and the
+// HashToPrev is SHA-256 over the encoding of the whole previous block.
+//
+// A chain is:
+//
+//	version  1 byte, EncodingVersion
+//	count    2 bytes, uint16, number of blocks
+//	blocks   count times: 2 bytes uint16 length + block encoding
+//
+// A revocation is encoded like a block but with its own tags:
+//
+//	0x01 BlockHash            bytes
+//	0x02 RevokerPubKey        bytes
+//	0x03 Signature            bytes
+//	0x04 Rotations            bytes, optional, chain encoding of the revoker's
+//	                          rotation blocks
+//
+// The revocation signature is over "ic-revocation-v1" || BlockHash.
+//
+// An attestation of the selective disclosure has its own tags:
+//
+//	0x01 Root                 bytes
+//	0x02 Leaf                 bytes
+//	0x03 MaxDepth             int64, 8 bytes
+//	0x04 Position             int64, two's complement, 8 bytes
+//	0x05 Commitment           bytes, SHA-256 of "ic-commitment-v1" || salt ||
+//	                          chain encoding
+//	0x06 NotBefore            int64, Unix time in seconds, 8 bytes
+//	0x07 NotAfter             int64, Unix time in seconds, 8 bytes
+//	0x08 Signature            bytes
+//
+// The attestation signature is over "ic-attestation-v1" || the encoding
+// without the Signature. A presentation of the attestation is:
+//
+//	0x01 Attestation          bytes, the attestation encoding
+//	0x02 Nonce                bytes
+//	0x03 Signature            bytes
+//
+// The presentation signature is over "ic-presentation-v1" || the encoding
+// without the Signature.
+//
+// Decoders must reject unknown versions and tags, out of order or duplicate
+// tags, zero length values, wrong integer sizes and trailing bytes.
+
+// EncodingVersion is the version byte of the current wire encoding.
+const EncodingVersion byte = 0x01
+
+const (
+	tagHashToPrev byte = 0x01 + iota
+	tagInviteePubKey
+	tagInvitersSignature
+	tagPosition
+	tagKind
+	tagNotBefore
+	tagNotAfter
+	tagAcceptanceSignature
+	tagAlgorithm
+)
+
+const (
+	tagRevokedHash byte = 0x01 + iota
+	tagRevokerPubKey
+	tagRevokerSignature
+	tagRevokerRotations
+)
+
+const (
+	tagAttestedRoot byte = 0x01 + iota
+	tagAttestedLeaf
+	tagAttestedMaxDepth
+	tagAttestedPosition
+	tagAttestedCommitment
+	tagAttestedNotBefore
+	tagAttestedNotAfter
+	tagAttestationSignature
+)
+
+const (
+	tagPresentedAttestation byte = 0x01 + iota
+	tagPresentationNonce
+	tagPresentationSignature
+)
+
+const int64Size = 8
+
+var errTooLong = errors.New("value too long")
+
+type encoder struct {
+	buf []byte
+	err error
+}
+
+func newEncoder() *encoder {
+	return &encoder{buf: []byte{EncodingVersion}}
+}
+
+func (e *encoder) uint16(v int) {
+	if e.err != nil {
+		return
+	}
+	if v > math.MaxUint16 {
+		e.err = errTooLong
+		return
+	}
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *encoder) bytes(tag byte, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	e.buf = append(e.buf, tag)
+	e.uint16(len(v))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) int(tag byte, v int) {
+	if v == 0 {
+		return
+	}
+	e.buf = append(e.buf, tag)
+	e.uint16(int64Size)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(int64(v)))
+}
+
+func (e *encoder) algorithm(tag byte, a crypto.Algorithm) {
+	if a == crypto.Ed25519 {
+		return
+	}
+	e.buf = append(e.buf, tag)
+	e.uint16(1)
+	e.buf = append(e.buf, byte(a))
+}
+
+func (e *encoder) kind(tag byte, k Kind) {
+	if k == KindInvite {
+		return
+	}
+	if !k.valid() {
+		e.err = fmt.Errorf("unknown block kind %d", k)
+		return
+	}
+	e.buf = append(e.buf, tag)
+	e.uint16(1)
+	e.buf = append(e.buf, byte(k))
+}
+
+func (e *encoder) result() ([]byte, error) {
+	return e.buf, e.err
+}
+
+type decoder struct {
+	d       []byte
+	lastTag byte
+	err     error
+}
+
+// newDecoder checks the version byte and returns the decoder for the rest.
+func newDecoder(d []byte) *decoder {
+	dec := &decoder{d: d}
+	if len(d) == 0 {
+		dec.fail("empty data")
+		return dec
+	}
+	if d[0] != EncodingVersion {
+		dec.fail("unknown version %d", d[0])
+	}
+	dec.d = d[1:]
+	return dec
+}
+
+func (dec *decoder) fail(format string, a ...any) {
+	if dec.err == nil {
+		dec.err = fmt.Errorf("%w: "+format, append([]any{ErrDecode}, a...)...)
+	}
+}
+
+func (dec *decoder) more() bool {
+	return dec.err == nil && len(dec.d) > 0
+}
+
+func (dec *decoder) next(n int) []byte {
+	if dec.err != nil {
+		return nil
+	}
+	if len(dec.d) < n {
+		dec.fail("unexpected end of data")
+		return nil
+	}
+	v := dec.d[:n]
+	dec.d = dec.d[n:]
+	return v
+}
+
+func (dec *decoder) uint16() int {
+	v := dec.next(2)
+	if v == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(v))
+}
+
+// field returns the next TLV field. It checks the canonical tag order and that
+// the value isn't empty.
+func (dec *decoder) field() (tag byte, v []byte) {
+	t := dec.next(1)
+	if t == nil {
+		return 0, nil
+	}
+	tag = t[0]
+	if tag <= dec.lastTag {
+		dec.fail("tag %d out of order", tag)
+		return 0, nil
+	}
+	dec.lastTag = tag
+	l := dec.uint16()
+	if dec.err == nil && l == 0 {
+		dec.fail("tag %d has empty value", tag)
+		return 0, nil
+	}
+	return tag, dec.next(l)
+}
+
+func (dec *decoder) int(v []byte) int {
+	if len(v) != int64Size {
+		dec.fail("integer size %d", len(v))
+		return 0
+	}
+	i := int(int64(binary.BigEndian.Uint64(v)))
+	if i == 0 {
+		dec.fail("zero integer must be omitted")
+	}
+	return i
+}
+
+// algorithm decodes the algorithm. Unknown algorithms are decoded, Verify
+// rejects them.
+func (dec *decoder) algorithm(v []byte) crypto.Algorithm {
+	if len(v) != 1 {
+		dec.fail("algorithm size %d", len(v))
+		return crypto.Ed25519
+	}
+	a := crypto.Algorithm(v[0])
+	if a == crypto.Ed25519 {
+		dec.fail("default algorithm must be omitted")
+	}
+	return a
+}
+
+func (dec *decoder) kind(v []byte) Kind {
+	if len(v) != 1 {
+		dec.fail("kind size %d", len(v))
+		return KindInvite
+	}
+	k := Kind(v[0])
+	if k == KindInvite || !k.valid() {
+		dec.fail("kind %d", k)
+	}
+	return k
+}
+
+// rotations decodes the revocation's rotation blocks, which are encoded like
+// a chain.
+func (dec *decoder) rotations(v []byte) []Block {
+	c, err := decodeChain(v)
+	if err != nil {
+		dec.fail("rotations: %v", err)
+		return nil
+	}
+	if c.Len() == 0 {
+		dec.fail("empty rotations must be omitted")
+	}
+	return c.Blocks
+}
+
+func (dec *decoder) result() error {
+	if dec.err == nil && len(dec.d) > 0 {
+		dec.fail("%d trailing bytes", len(dec.d))
+	}
+	return dec.err
+}
+
+func encodeBlock(b Block) ([]byte, error) {
+	enc := newEncoder()
+	enc.bytes(tagHashToPrev, b.HashToPrev)
+	enc.bytes(tagInviteePubKey, b.InviteePubKey)
+	enc.bytes(tagInvitersSignature, b.InvitersSignature)
+	enc.int(tagPosition, int(b.Position))
+	enc.kind(tagKind, b.Kind)
+	enc.int(tagNotBefore, toUnix(b.NotBefore))
+	enc.int(tagNotAfter, toUnix(b.NotAfter))
+	enc.bytes(tagAcceptanceSignature, b.AcceptanceSignature)
+	enc.algorithm(tagAlgorithm, b.Algorithm)
+	return enc.result()
+}
+
+func decodeBlock(d []byte) (b Block, err error) {
+	dec := newDecoder(d)
+	for dec.more() {
+		tag, v := dec.field()
+		switch tag {
+		case 0: // decoding error is already set
+		case tagHashToPrev:
+			b.HashToPrev = clone(v)
+		case tagInviteePubKey:
+			b.InviteePubKey = clone(v)
+		case tagInvitersSignature:
+			b.InvitersSignature = clone(v)
+		case tagPosition:
+			b.Position = Position(dec.int(v))
+		case tagKind:
+			b.Kind = dec.kind(v)
+		case tagNotBefore:
+			b.NotBefore = fromUnix(dec.int(v))
+		case tagNotAfter:
+			b.NotAfter = fromUnix(dec.int(v))
+		case tagAcceptanceSignature:
+			b.AcceptanceSignature = clone(v)
+		case tagAlgorithm:
+			b.Algorithm = dec.algorithm(v)
+		default:
+			dec.fail("unknown tag %d", tag)
+		}
+	}
+	return b, dec.result()
+}
+
+func encodeChain(c Chain) ([]byte, error) {
+	enc := newEncoder()
+	enc.uint16(c.Len())
+	for _, b := range c.Blocks {
+		d, err := encodeBlock(b)
+		if err != nil {
+			return nil, err
+		}
+		enc.uint16(len(d))
+		enc.buf = append(enc.buf, d...)
+	}
+	return enc.result()
+}
+
+func decodeChain(d []byte) (c Chain, err error) {
+	dec := newDecoder(d)
+	count := dec.uint16()
+	if dec.err == nil && count > 0 {
+		c.Blocks = make([]Block, 0, count)
+	}
+	for i := 0; i < count && dec.err == nil; i++ {
+		bd := dec.next(dec.uint16())
+		if dec.err != nil {
+			break
+		}
+		b, err := decodeBlock(bd)
+		if err != nil {
+			return Nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		c.Blocks = append(c.Blocks, b)
+	}
+	if err := dec.result(); err != nil {
+		return Nil, err
+	}
+	return c, nil
+}
+
+// clone copies the decoded slice that it doesn't share memory with the input.
+func clone(v []byte) []byte {
+	return append([]byte(nil), v...)
+}
@@ -0,0 +1,144 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+var update = flag.Bool("update", false, "update golden test vectors")
+
+var vectorsFile = filepath.Join("testdata", "vectors.json")
+
+// vectors are the golden test vectors for the other language implementations.
+// All of the binary data is hex encoded.
+type vectors struct {
+	Keys    []vectorKey   `json:"keys"`
+	Blocks  []vectorBlock `json:"blocks"`
+	Chain   string        `json:"chain"`
+	Invalid []string      `json:"invalid_blocks"`
+}
+
+type vectorKey struct {
+	Seed   string `json:"seed"`
+	PubKey string `json:"pub_key"`
+}
+
+type vectorBlock struct {
+	SigningInput string `json:"signing_input"`
+	Signature    string `json:"signature"`
+	Encoding     string `json:"encoding"`
+	Hash         string `json:"hash"`
+}
+
+func buildVectors() (v vectors) {
+	keys := make([]crypto.Key, 4)
+	for i := range keys {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, 32)
+		keys[i] = crypto.NewKeyFromSeed(seed)
+		v.Keys = append(v.Keys, vectorKey{
+			Seed:   hex.EncodeToString(seed),
+			PubKey: hex.EncodeToString(keys[i].PubKey),
+		})
+	}
+	c := NewRootChain(keys[0].PubKey)
+	c = c.Invite(keys[0], keys[1].PubKey, 1)
+	c = c.Invite(keys[1], keys[2].PubKey, 3)
+	c = c.Rotate(keys[2], keys[3].PubKey)
+
+	for _, b := range c.Blocks {
+		v.Blocks = append(v.Blocks, vectorBlock{
+			SigningInput: hex.EncodeToString(b.ExcludeSign().Bytes()),
+			Signature:    hex.EncodeToString(b.InvitersSignature),
+			Encoding:     hex.EncodeToString(b.Bytes()),
+			Hash:         hex.EncodeToString(b.Hash()),
+		})
+	}
+	v.Chain = hex.EncodeToString(c.Bytes())
+	v.Invalid = []string{
+		"",                           // no version
+		"02",                         // unknown version
+		"010200",                     // zero length
+		"0102000101010001",           // tags out of order
+		"01010001aa010001aa",         // duplicate tag
+		"010400020001",               // integer size
+		"0104000800000000000000",     // truncated
+		"01040008000000000000000000", // zero integer
+		"010a0001aa",                 // unknown tag
+		"01010001aa00",               // trailing bytes
+		"0105000100",                 // zero kind
+		"0105000109",                 // unknown kind
+	}
+	return v
+}
+
+func TestGoldenVectors(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	got := buildVectors()
+	if *update {
+		d := try.To1(json.MarshalIndent(got, "", "  "))
+		try.To(os.WriteFile(vectorsFile, append(d, '\n'), 0o600))
+	}
+	var want vectors
+	try.To(json.Unmarshal(try.To1(os.ReadFile(vectorsFile)), &want))
+	assert.DeepEqual(got, want)
+
+	c := try.To1(ParseChain(try.To1(hex.DecodeString(want.Chain))))
+	assert.That(c.Verify())
+	for i, vb := range want.Blocks {
+		b := try.To1(ParseBlock(try.To1(hex.DecodeString(vb.Encoding))))
+		assert.That(EqualBlocks(b, c.Blocks[i]))
+		if i > 0 {
+			pubKey := try.To1(hex.DecodeString(want.Keys[i-1].PubKey))
+			assert.That(crypto.VerifySign(pubKey,
+				try.To1(hex.DecodeString(vb.SigningInput)),
+				try.To1(hex.DecodeString(vb.Signature))))
+		}
+	}
+	for _, s := range want.Invalid {
+		_, err := ParseBlock(try.To1(hex.DecodeString(s)))
+		assert.That(errors.Is(err, ErrDecode), s)
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, c := range []Chain{testChain, alice.Chain, bob.Chain} {
+		d := c.Bytes()
+		assert.DeepEqual(try.To1(ParseChain(d)).Bytes(), d)
+	}
+	b := Block{HashToPrev: make([]byte, 1<<16)}
+	_, err := b.TryBytes()
+	assert.Error(err)
+}
+
+func TestEncodingAlgorithm(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	key := try.To1(crypto.GenerateKey(crypto.P256))
+	c := alice.Invite(alice.Key, key.PubKey, 1)
+	d := c.Bytes()
+	c2 := try.To1(ParseChain(d))
+	assert.Equal(c2.Blocks[2].Algorithm, crypto.P256)
+	assert.That(c2.Verify())
+	assert.DeepEqual(c2.Bytes(), d)
+
+	for _, s := range []string{
+		"0109000100",   // default algorithm
+		"010900020101", // algorithm size
+	} {
+		_, err := ParseBlock(try.To1(hex.DecodeString(s)))
+		assert.That(errors.Is(err, ErrDecode), s)
+	}
+}
@@ -0,0 +1,24 @@
+package chain
+
+import "errors"
+
+// Sentinel errors returned by the error-returning variants of the chain API,
+// e.g. ParseChain and Chain.TryInvite. Use errors.Is to check them because
+// they are usually wrapped with call annotations.
+var (
+	ErrNotLeaf      = errors.New("only leaf can invite")
+	ErrEmptyChain   = errors.New("chain cannot be empty")
+	ErrDecode       = errors.New("cannot decode")
+	ErrBadSignature = errors.New("bad signature")
+	ErrHashLink     = errors.New("hash link to previous block broken")
+	ErrBadRoot      = errors.New("bad root block")
+	ErrRotation     = errors.New("bad key rotation")
+	ErrRevoked      = errors.New("block is revoked")
+	ErrNotAncestor  = errors.New("revoker isn't an ancestor")
+	ErrExpired      = errors.New("block isn't valid at the time")
+	ErrPosition     = errors.New("position escalates privileges")
+	ErrOffer        = errors.New("bad invitation offer")
+	ErrAcceptance   = errors.New("bad acceptance signature")
+	ErrDisclosure   = errors.New("bad selective disclosure")
+	ErrAlgorithm    = errors.New("bad key algorithm")
+)
@@ -0,0 +1,145 @@
+package chain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lainio/ic/crypto"
+)
+
+// Base64 is a byte slice that's JSON encoded as unpadded base64url string, i.e.
+// like the command-line tool prints the keys. Use it for the keys and the
+// signatures of the JSON APIs.
+type Base64 []byte
+
+func (b Base64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Base64) UnmarshalJSON(d []byte) error {
+	var s string
+	if err := json.Unmarshal(d, &s); err != nil {
+		return jsonErr(err)
+	}
+	v, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return jsonErr(err)
+	}
+	if len(v) == 0 {
+		v = nil // keep the encoding canonical, see encodeBlock
+	}
+	*b = v
+	return nil
+}
+
+type jsonBlock struct {
+	HashToPrev          Base64           `json:"hash_to_prev,omitempty"`
+	InviteePubKey       Base64           `json:"invitee_pub_key"`
+	InvitersSignature   Base64           `json:"inviters_signature,omitempty"`
+	Position            Position         `json:"position,omitempty"`
+	Kind                Kind             `json:"kind,omitempty"`
+	NotBefore           *time.Time       `json:"not_before,omitempty"`
+	NotAfter            *time.Time       `json:"not_after,omitempty"`
+	AcceptanceSignature Base64           `json:"acceptance_signature,omitempty"`
+	Algorithm           crypto.Algorithm `json:"algorithm,omitempty"`
+}
+
+type jsonChain struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// MarshalJSON encodes the block with base64url (no padding) binary fields.
+func (b Block) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBlock{
+		HashToPrev:          b.HashToPrev,
+		InviteePubKey:       b.InviteePubKey,
+		InvitersSignature:   b.InvitersSignature,
+		Position:            b.Position,
+		Kind:                b.Kind,
+		NotBefore:           jsonTime(b.NotBefore),
+		NotAfter:            jsonTime(b.NotAfter),
+		AcceptanceSignature: b.AcceptanceSignature,
+		Algorithm:           b.Algorithm,
+	})
+}
+
+func (b *Block) UnmarshalJSON(d []byte) error {
+	var jb jsonBlock
+	if err := json.Unmarshal(d, &jb); err != nil {
+		return jsonErr(err)
+	}
+	*b = Block{
+		HashToPrev:          jb.HashToPrev,
+		InviteePubKey:       jb.InviteePubKey,
+		InvitersSignature:   jb.InvitersSignature,
+		Position:            jb.Position,
+		Kind:                jb.Kind,
+		AcceptanceSignature: jb.AcceptanceSignature,
+		Algorithm:           jb.Algorithm,
+	}
+	if jb.NotBefore != nil {
+		b.NotBefore = jb.NotBefore.UTC()
+	}
+	if jb.NotAfter != nil {
+		b.NotAfter = jb.NotAfter.UTC()
+	}
+	return nil
+}
+
+// MarshalJSON encodes the chain as a JSON object. The encoding is lossless,
+// i.e. the decoded chain verifies if the original chain did.
+func (c Chain) MarshalJSON() ([]byte, error) {
+	jc := jsonChain{Blocks: c.Blocks}
+	if jc.Blocks == nil {
+		jc.Blocks = []Block{}
+	}
+	return json.Marshal(jc)
+}
+
+func (c *Chain) UnmarshalJSON(d []byte) error {
+	var jc jsonChain
+	if err := json.Unmarshal(d, &jc); err != nil {
+		return jsonErr(err)
+	}
+	c.Blocks = jc.Blocks
+	return nil
+}
+
+// String returns compact, human-readable presentation of the chain for logs and
+// debugging. It includes root and leaf key fingerprints and the positions of
+// the blocks.
+func (c Chain) String() string {
+	if c.Len() == 0 {
+		return "chain[]"
+	}
+	positions := make([]string, 0, c.Len())
+	for _, b := range c.Blocks {
+		positions = append(positions, fmt.Sprint(int(b.Position)))
+	}
+	return fmt.Sprintf("chain[root=%s leaf=%s len=%d positions=%s]",
+		crypto.Fingerprint(c.firstBlock().InviteePubKey),
+		crypto.Fingerprint(c.lastBlock().InviteePubKey),
+		c.Len(),
+		strings.Join(positions, ","),
+	)
+}
+
+// jsonTime returns nil for zero time to omit it from JSON.
+func jsonTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// jsonErr returns err as ErrDecode if it isn't already.
+func jsonErr(err error) error {
+	if errors.Is(err, ErrDecode) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrDecode, err)
+}
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, c := range []Chain{root.Chain, alice.Chain, testChain} {
+		d := try.To1(json.Marshal(c))
+		assert.ThatNot(strings.ContainsAny(string(d), "+/="),
+			"binary fields are base64url without padding")
+
+		var c2 Chain
+		try.To(json.Unmarshal(d, &c2))
+		assert.That(c2.Verify())
+		assert.DeepEqual(c2.Bytes(), c.Bytes())
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c := NewRootChain(crypto.NewKeyFromSeed(make([]byte, 32)).PubKey)
+	d := try.To1(json.Marshal(c))
+	assert.Equal(string(d), `{"blocks":[{"invitee_pub_key":`+
+		`"O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik"}]}`)
+
+	d = try.To1(json.Marshal(Nil))
+	assert.Equal(string(d), `{"blocks":[]}`)
+}
+
+func TestJSONDecodeError(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	var c Chain
+	err := json.Unmarshal([]byte(`{"blocks":[{"invitee_pub_key":"+/+"}]}`), &c)
+	assert.That(errors.Is(err, ErrDecode))
+	err = json.Unmarshal([]byte(`{"blocks":[{"position":"1"}]}`), &c)
+	assert.That(errors.Is(err, ErrDecode))
+	err = json.Unmarshal([]byte(`[]`), &c)
+	assert.That(errors.Is(err, ErrDecode))
+}
+
+func TestString(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	assert.Equal(Nil.String(), "chain[]")
+
+	s := alice.String()
+	assert.That(strings.Contains(s, "root="+crypto.Fingerprint(root.PubKey)))
+	assert.That(strings.Contains(s, "leaf="+crypto.Fingerprint(alice.PubKey)))
+	assert.That(strings.Contains(s, "len=2 positions=0,1"))
+}
+
+func TestJSONAlgorithm(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	key := try.To1(crypto.GenerateKey(crypto.P256))
+	c := NewRootChain(key.PubKey)
+	d := try.To1(json.Marshal(c))
+	assert.That(strings.Contains(string(d), `"algorithm":"p256"`))
+
+	var c2 Chain
+	try.To(json.Unmarshal(d, &c2))
+	assert.DeepEqual(c2.Bytes(), c.Bytes())
+
+	d = []byte(`{"blocks":[{"invitee_pub_key":"AA","algorithm":"rsa"}]}`)
+	assert.That(errors.Is(json.Unmarshal(d, &c2), ErrDecode))
+}
@@ -0,0 +1,136 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+// acceptDomain separates the acceptance signatures from the other signatures.
+const acceptDomain = "ic-accept-v1"
+
+// Offer is the first phase of the two-phase invitation. Invite is one-sided:
+// the inviter signs the invitee's public key without any proof that the
+// invitee holds the private key. With the two-phase invitation:
+//
+//  1. The inviter creates an Offer with Chain.Offer and sends it to the
+//     invitee.
+//  2. The invitee checks the offer and accepts it with Offer.Accept, which
+//     signs the offered block as a proof-of-possession of the key.
+//  3. The inviter finalizes the block with Chain.Countersign. The acceptance
+//     signature is stored to the block and the inviter signs it as well.
+//
+// Verify checks the acceptance signatures of the blocks which have them.
+type Offer struct {
+	Chain Chain `json:"chain"` // inviter's chain
+	Block Block `json:"block"` // offered block without signatures
+}
+
+// Acceptance is the invitee's proof-of-possession over the Offer.
+type Acceptance struct {
+	Signature crypto.Signature `json:"signature"`
+}
+
+// Offer is called for the inviter's chain. It returns an offer of the block to
+// the inviteesPubKey at the position. The offer isn't signed, i.e. it doesn't
+// grant anything before Countersign.
+func (c Chain) Offer(
+	inviteesPubKey crypto.PubKey,
+	position Position,
+	validity Validity,
+) (o Offer, err error) {
+	defer err2.Handle(&err)
+
+	if c.Len() == 0 {
+		return o, ErrEmptyChain
+	}
+	if !c.Position().CanInvite(position) {
+		return o, fmt.Errorf("%w: %v cannot invite %v",
+			ErrPosition, c.Position(), position)
+	}
+	alg := try.To1(algorithmOf(inviteesPubKey))
+	return Offer{
+		Chain: try.To1(c.TryClone()),
+		Block: Block{
+			HashToPrev:    c.hashToLeaf(),
+			InviteePubKey: inviteesPubKey,
+			Position:      position,
+			Validity:      validity.truncate(),
+			Algorithm:     alg,
+		},
+	}, nil
+}
+
+// Accept is called by the invitee. It checks that the offer is for the
+// inviteeKey and from a valid chain, and signs it.
+func (o Offer) Accept(inviteeKey crypto.KeyHandle) (a Acceptance, err error) {
+	defer err2.Handle(&err)
+
+	try.To(o.check())
+	if !crypto.EqualBytes(o.Block.InviteePubKey, inviteeKey.PublicKey()) {
+		return a, fmt.Errorf("%w: offer is for other key", ErrOffer)
+	}
+	return Acceptance{
+		Signature: inviteeKey.Sign(try.To1(o.Block.acceptanceInput())),
+	}, nil
+}
+
+// Countersign is the last phase of the two-phase invitation. It's called for
+// the inviter's chain with the offer and the invitee's acceptance of it. It
+// returns ErrAcceptance if the acceptance signature doesn't verify, and ErrOffer
+// if the offer isn't for this chain. Otherwise, it works like TryInvite.
+func (c Chain) Countersign(
+	invitersKey crypto.KeyHandle,
+	o Offer,
+	a Acceptance,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	try.To(o.check())
+	if !crypto.EqualBytes(o.Block.HashToPrev, c.hashToLeaf()) {
+		return Nil, fmt.Errorf("%w: offer is for other chain", ErrOffer)
+	}
+	b := o.Block
+	b.AcceptanceSignature = a.Signature
+	if !b.VerifyAcceptance() {
+		return Nil, ErrAcceptance
+	}
+	return c.addBlock(invitersKey, b)
+}
+
+// check checks that the offered block is unsigned and links to the valid chain.
+func (o Offer) check() error {
+	if err := o.Chain.TryVerify(); err != nil {
+		return fmt.Errorf("%w: %v", ErrOffer, err)
+	}
+	b := o.Block
+	if len(b.InvitersSignature) != 0 || len(b.AcceptanceSignature) != 0 ||
+		b.Kind != KindInvite || !b.validAlgorithm() ||
+		!crypto.EqualBytes(b.HashToPrev, o.Chain.hashToLeaf()) {
+		return fmt.Errorf("%w: malformed block", ErrOffer)
+	}
+	return nil
+}
+
+// VerifyAcceptance verifies the invitee's acceptance signature of the block.
+func (b Block) VerifyAcceptance() bool {
+	d, err := b.acceptanceInput()
+	if err != nil {
+		return false
+	}
+	return crypto.VerifySign(b.InviteePubKey, d, b.AcceptanceSignature)
+}
+
+// acceptanceInput returns the bytes the invitee signs, i.e. the block without
+// any signatures and with acceptDomain prefix.
+func (b Block) acceptanceInput() ([]byte, error) {
+	b.InvitersSignature = nil
+	b.AcceptanceSignature = nil
+	d, err := b.TryBytes()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(acceptDomain), d...), nil
+}
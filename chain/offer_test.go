@@ -0,0 +1,102 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestOffer(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	o := try.To1(bob.Offer(carolKey.PubKey, 1, ValidFor(time.Hour)))
+	a := try.To1(o.Accept(carolKey))
+	carol := try.To1(bob.Countersign(bob.Key, o, a))
+	assert.That(carol.Verify())
+	assert.Equal(carol.Len(), 3)
+	assert.That(carol.Blocks[2].VerifyAcceptance())
+	assert.That(bob.IsInviterFor(carol))
+
+	// the acceptance survives the encodings
+	assert.That(NewChainFromData(carol.Bytes()).Verify())
+	var c Chain
+	try.To(json.Unmarshal(try.To1(json.Marshal(carol)), &c))
+	assert.That(c.Verify())
+	assert.That(EqualBlocks(c.Blocks[2], carol.Blocks[2]))
+
+	// offers can be transported as JSON
+	var o2 Offer
+	try.To(json.Unmarshal(try.To1(json.Marshal(o)), &o2))
+	a2 := try.To1(o2.Accept(carolKey))
+	assert.That(try.To1(bob.Countersign(bob.Key, o2, a2)).Verify())
+}
+
+func TestOfferFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	o := try.To1(bob.Offer(carolKey.PubKey, 1, Validity{}))
+
+	// only the invitee can accept
+	_, err := o.Accept(crypto.NewKey())
+	assert.That(errors.Is(err, ErrOffer))
+
+	// the acceptance must be the invitee's
+	a := try.To1(o.Accept(carolKey))
+	forged := Acceptance{Signature: crypto.NewKey().Sign([]byte("x"))}
+	_, err = bob.Countersign(bob.Key, o, forged)
+	assert.That(errors.Is(err, ErrAcceptance))
+
+	// and over the offered block
+	o2 := o
+	o2.Block.Position = 2
+	_, err = bob.Countersign(bob.Key, o2, a)
+	assert.That(errors.Is(err, ErrAcceptance))
+
+	// only the inviter can countersign
+	_, err = alice.Countersign(alice.Key, o, a)
+	assert.That(errors.Is(err, ErrOffer))
+	_, err = bob.Countersign(alice.Key, o, a)
+	assert.That(errors.Is(err, ErrNotLeaf))
+
+	// no escalation
+	_, err = bob.Offer(carolKey.PubKey, RootPosition, Validity{})
+	assert.That(errors.Is(err, ErrPosition))
+	_, err = Nil.Offer(carolKey.PubKey, 1, Validity{})
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestAcceptanceVerify(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	o := try.To1(bob.Offer(carolKey.PubKey, 1, Validity{}))
+	carol := try.To1(bob.Countersign(bob.Key, o, try.To1(o.Accept(carolKey))))
+
+	// the inviter's signature covers the acceptance
+	c := carol.Clone()
+	c.Blocks[2].AcceptanceSignature = nil
+	assert.Equal(c.VerifyReport().Failure, FailSignature)
+
+	// a bad acceptance is detected even if the inviter signed it
+	b := carol.Blocks[2]
+	b.AcceptanceSignature = crypto.NewKey().Sign([]byte("x"))
+	b.InvitersSignature = bob.Key.Sign(b.ExcludeSign().Bytes())
+	c = bob.Clone()
+	c.Blocks = append(c.Blocks, b)
+	r := c.VerifyReport()
+	assert.Equal(r.Failure, FailAcceptance)
+	assert.Equal(r.Block, 2)
+	assert.That(errors.Is(c.TryVerify(), ErrAcceptance))
+
+	// the root cannot have an acceptance
+	c = alice.Clone()
+	c.Blocks[0].AcceptanceSignature = alice.Key.Sign([]byte("x"))
+	assert.Equal(c.VerifyReport().Failure, FailRoot)
+}
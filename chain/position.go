@@ -0,0 +1,63 @@
+package chain
+
+import "fmt"
+
+// Position is the rank, i.e. the role, of the member in the chain. The smaller
+// the value the more privileges the member has. Root is always RootPosition,
+// and an inviter cannot give a better position than it has itself, i.e. the
+// positions never decrease when going from the root to the leaf. Verify
+// enforces the rule.
+//
+// Applications define the meaning of the positions. The predefined positions
+// are for the most common case: admins can invite members, members can invite
+// guests, and guests can invite only other guests.
+type Position int
+
+const (
+	RootPosition Position = iota
+	AdminPosition
+	MemberPosition
+	GuestPosition
+)
+
+var positionNames = map[Position]string{
+	RootPosition:   "root",
+	AdminPosition:  "admin",
+	MemberPosition: "member",
+	GuestPosition:  "guest",
+}
+
+func (p Position) String() string {
+	if name, ok := positionNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("position(%d)", int(p))
+}
+
+// CanInvite tells if the member at the position p can invite a member to the
+// position q, i.e. there isn't privilege escalation.
+func (p Position) CanInvite(q Position) bool {
+	return q >= p
+}
+
+// Position returns the effective position of the chain's leaf member. For the
+// empty chain it returns NotConnected.
+func (c Chain) Position() Position {
+	if c.Len() == 0 {
+		return NotConnected
+	}
+	return c.lastBlock().Position
+}
+
+// PositionAt returns the position of the member at the level, i.e. the distance
+// from the root. Rotation blocks aren't counted as levels, see Depth. If there
+// is no member at the level it returns NotConnected.
+func (c Chain) PositionAt(level int) Position {
+	m := c.members()
+	if level < 0 || level >= len(m) {
+		return NotConnected
+	}
+	// member's latest block has its current position, but thanks to Verify
+	// rotations cannot change it
+	return c.Blocks[m[level]].Position
+}
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestPosition(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	assert.Equal(root.Position(), RootPosition)
+	assert.Equal(alice.Position(), AdminPosition)
+	assert.Equal(Nil.Position(), Position(NotConnected))
+	assert.Equal(MemberPosition.String(), "member")
+	assert.Equal(Position(7).String(), "position(7)")
+
+	assert.That(AdminPosition.CanInvite(MemberPosition))
+	assert.That(MemberPosition.CanInvite(MemberPosition))
+	assert.ThatNot(GuestPosition.CanInvite(MemberPosition))
+}
+
+func TestInvitePosition(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// admin invites a member and the member invites a guest
+	memberKey := crypto.NewKey()
+	member := alice.Invite(alice.Key, memberKey.PubKey, MemberPosition)
+	guestKey := crypto.NewKey()
+	guest := member.Invite(memberKey, guestKey.PubKey, GuestPosition)
+	assert.That(guest.Verify())
+	assert.Equal(guest.Position(), GuestPosition)
+	assert.Equal(guest.PositionAt(0), RootPosition)
+	assert.Equal(guest.PositionAt(2), MemberPosition)
+	assert.Equal(guest.PositionAt(4), Position(NotConnected))
+
+	// guest cannot invite members, and member cannot invite admins
+	_, err := guest.TryInvite(guestKey, crypto.NewKey().PubKey, MemberPosition)
+	assert.That(errors.Is(err, ErrPosition))
+	_, err = member.TryInvite(memberKey, crypto.NewKey().PubKey, AdminPosition)
+	assert.That(errors.Is(err, ErrPosition))
+
+	// rotation keeps the position
+	rotated := member.Rotate(memberKey, crypto.NewKey().PubKey)
+	assert.Equal(rotated.Position(), MemberPosition)
+	assert.Equal(rotated.PositionAt(2), MemberPosition)
+}
+
+func TestVerifyPosition(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// forge an escalated block by signing it directly
+	memberKey := crypto.NewKey()
+	member := alice.Invite(alice.Key, memberKey.PubKey, MemberPosition)
+	b := Block{
+		HashToPrev:    member.hashToLeaf(),
+		InviteePubKey: crypto.NewKey().PubKey,
+		Position:      AdminPosition,
+	}
+	b.InvitersSignature = memberKey.Sign(b.Bytes())
+	member.Blocks = append(member.Blocks, b)
+
+	r := member.VerifyReport()
+	assert.Equal(r.Failure, FailPosition)
+	assert.Equal(r.Block, 3)
+	assert.That(errors.Is(member.TryVerify(), ErrPosition))
+
+	c := NewRootChain(crypto.NewKey().PubKey)
+	c.Blocks[0].Position = AdminPosition
+	assert.Equal(c.VerifyReport().Failure, FailPosition)
+}
@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+// pseudonymDomain separates the pseudonym key derivation from the other uses
+// of the member's key.
+const pseudonymDomain = "ic-pseudonym-v1"
+
+// Pseudonym is called for the chain holder's own chain to get a pairwise
+// pseudonymous sub-chain for a single relationship. The leaf key signs the
+// pseudonymPubKey, which should be a fresh key, e.g. from PseudonymKey, that
+// is used only with the one verifier. The holder answers the challenges with
+// the pseudonym key.
+//
+// The pseudonym chain has the same members at the same depths, i.e. Hops,
+// CommonInviter and IsInviterFor give the same results as for the original
+// chain.
+//
+// The pseudonym chain includes the original chain, which is needed to verify
+// it, i.e. the verifiers who see it can link the holder's pseudonyms. That's
+// why the pseudonym chain is shown only to the root, which attests it for the
+// relationship, see Attest. The holder presents the attestation to the one
+// verifier with the pseudonym key, see Attestation.Present. The presentations
+// reveal only the root, the pseudonym key, the position, the depth bound and
+// the validity, so the verifiers cannot link the pseudonyms of the same holder
+// as long as the root uses the same maxDepth and validity windows for
+// everyone. The root can link them.
+func (c Chain) Pseudonym(
+	leafKey crypto.KeyHandle,
+	pseudonymPubKey crypto.PubKey,
+) (nc Chain) {
+	return try.To1(c.TryPseudonym(leafKey, pseudonymPubKey))
+}
+
+// TryPseudonym is error returning version of Pseudonym. It returns
+// ErrEmptyChain if the chain has no blocks, ErrNotLeaf if leafKey isn't the
+// leaf key of the chain, and ErrAlgorithm if the pseudonymPubKey is malformed.
+func (c Chain) TryPseudonym(
+	leafKey crypto.KeyHandle,
+	pseudonymPubKey crypto.PubKey,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	if c.Len() == 0 {
+		return Nil, ErrEmptyChain
+	}
+	return c.addBlock(leafKey, Block{
+		InviteePubKey: pseudonymPubKey,
+		Position:      c.lastBlock().Position,
+		Kind:          KindPseudonym,
+	})
+}
+
+// IsPseudonym tells if the chain is a pseudonym chain, see Pseudonym.
+func (c Chain) IsPseudonym() bool {
+	return c.Len() > 0 && c.lastBlock().Kind == KindPseudonym
+}
+
+// PseudonymKey derives the pseudonym key of the relationship from the
+// member's key. The same relationship, e.g. the verifier's public key, always
+// gives the same key, which means that the holder doesn't need to store the
+// pseudonym keys. The pseudonym key has the algorithm of the member's key.
+func PseudonymKey(key crypto.Key, relationship []byte) crypto.Key {
+	return key.Derive(append([]byte(pseudonymDomain), relationship...))
+}
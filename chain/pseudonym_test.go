@@ -0,0 +1,185 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestPseudonym(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+
+	verifier := crypto.NewKey()
+	pseudoKey := PseudonymKey(carolKey, verifier.PubKey)
+	pseudo := carol.Pseudonym(carolKey, pseudoKey.PubKey)
+	assert.That(pseudo.Verify())
+	assert.That(pseudo.IsPseudonym())
+	assert.That(!carol.IsPseudonym())
+	assert.Equal(pseudo.lastBlock().Kind, KindPseudonym)
+	assert.DeepEqual(pseudo.LeafPubKey(), pseudoKey.PubKey)
+	assert.Equal(pseudo.Position(), carol.Position())
+
+	// pseudonym chain is the same member at the same depth
+	assert.Equal(pseudo.Depth(), carol.Depth())
+	h1, l1 := alice.Hops(carol)
+	h2, l2 := alice.Hops(pseudo)
+	assert.Equal(h1, h2)
+	assert.Equal(l1, l2)
+	h1, l1 = bob.Hops(carol)
+	h2, l2 = bob.Hops(pseudo)
+	assert.Equal(h1, h2)
+	assert.Equal(l1, l2)
+	assert.Equal(CommonInviter(alice.Chain, pseudo), CommonInviter(alice.Chain, carol))
+	assert.That(bob.IsInviterFor(pseudo))
+	assert.That(SameInviter(pseudo, carol))
+	assert.DeepEqual(try.To1(pseudo.TryInviterPubKey()), bob.PubKey)
+
+	// pseudonym key answers the challenges, the original key doesn't
+	pinCode := 1234
+	respond := func(k crypto.Key) func(d []byte) crypto.Signature {
+		return func(d []byte) crypto.Signature {
+			return try.To1(SignChallenge(k.Handle(), d, pinCode))
+		}
+	}
+	assert.That(pseudo.Challenge(pinCode, respond(pseudoKey)))
+	assert.That(!pseudo.Challenge(pinCode, respond(carolKey)))
+
+	// only the leaf key can make a pseudonym
+	_, err := carol.TryPseudonym(bob.Key, crypto.NewKey().PubKey)
+	assert.That(errors.Is(err, ErrNotLeaf))
+	_, err = Nil.TryPseudonym(carolKey, crypto.NewKey().PubKey)
+	assert.That(errors.Is(err, ErrEmptyChain))
+}
+
+func TestPseudonymUnlinkable(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	validity := Validity{NotAfter: time.Now().Add(time.Hour)}
+
+	// carol gets the pseudonym of both verifiers attested by the root and
+	// presents them
+	present := func(verifier string, nonce []byte) Presentation {
+		pseudoKey := PseudonymKey(carolKey, []byte(verifier))
+		pseudo := carol.Pseudonym(carolKey, pseudoKey.PubKey)
+		a, _ := Attest(root.Key, pseudo, 3, validity)
+		d := a.Present(pseudoKey, nonce).Bytes()
+		return try.To1(ParsePresentation(d))
+	}
+	nonce1, nonce2 := crypto.RandSlice(32), crypto.RandSlice(32)
+	p1, p2 := present("verifier 1", nonce1), present("verifier 2", nonce2)
+	try.To(p1.Verify(root.PubKey, 3, nonce1, time.Now()))
+	try.To(p2.Verify(root.PubKey, 3, nonce2, time.Now()))
+
+	// the verifiers see none of carol's keys or blocks
+	for _, p := range []Presentation{p1, p2} {
+		d := p.Bytes()
+		assert.That(!containsKey(d, carolKey.PubKey))
+		assert.That(!containsKey(d, bob.PubKey))
+		for _, b := range carol.Blocks[1:] {
+			assert.That(!containsKey(d, b.Hash()))
+			assert.That(!containsKey(d, b.InvitersSignature))
+		}
+	}
+
+	// the per-relationship parts differ, the rest is the same for everyone
+	// who the root attests with the same parameters
+	a1, a2 := p1.Attestation, p2.Attestation
+	assert.That(!crypto.EqualBytes(a1.Leaf, a2.Leaf))
+	assert.That(!crypto.EqualBytes(a1.Commitment, a2.Commitment))
+	assert.That(!crypto.EqualBytes(a1.Signature, a2.Signature))
+	a1.Leaf, a1.Commitment, a1.Signature = nil, nil, nil
+	a2.Leaf, a2.Commitment, a2.Signature = nil, nil, nil
+	assert.DeepEqual(a1, a2)
+}
+
+func TestPseudonymKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	key := crypto.NewKey()
+	k1 := PseudonymKey(key, []byte("verifier 1"))
+	k2 := PseudonymKey(key, []byte("verifier 2"))
+	assert.DeepEqual(PseudonymKey(key, []byte("verifier 1")), k1)
+	assert.That(!k1.PubKeyEqual(k2.PubKey))
+	assert.That(!k1.PubKeyEqual(key.PubKey))
+	assert.That(k1.VerifySign([]byte("msg"), k1.Sign([]byte("msg"))))
+
+	// other keys give other pseudonyms for the same relationship
+	assert.That(!PseudonymKey(crypto.NewKey(), []byte("verifier 1")).
+		PubKeyEqual(k1.PubKey))
+
+	// pseudonym key has the algorithm of the member's key
+	p256Key := try.To1(crypto.GenerateKey(crypto.P256))
+	k3 := PseudonymKey(p256Key, []byte("verifier 1"))
+	assert.Equal(k3.Algorithm(), crypto.P256)
+	assert.DeepEqual(PseudonymKey(p256Key, []byte("verifier 1")), k3)
+	assert.That(k3.VerifySign([]byte("msg"), k3.Sign([]byte("msg"))))
+}
+
+func TestPseudonymPerRelationship(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	daveKey := crypto.NewKey()
+	dave := alice.Invite(alice.Key, daveKey.PubKey, 1)
+
+	p1 := dave.Pseudonym(daveKey, PseudonymKey(daveKey, []byte("v1")).PubKey)
+	p2 := dave.Pseudonym(daveKey, PseudonymKey(daveKey, []byte("v2")).PubKey)
+	assert.That(!crypto.EqualBytes(p1.LeafPubKey(), p2.LeafPubKey()))
+
+	// verifiers compute the same distances from the both pseudonyms
+	h1, l1 := p1.Hops(bob.Chain)
+	h2, l2 := p2.Hops(bob.Chain)
+	assert.Equal(h1, h2)
+	assert.Equal(l1, l2)
+	assert.Equal(CommonInviter(p1, p2), 1)
+
+	// the member can still invite with its own chain after the pseudonyms
+	erin := dave.Invite(daveKey, crypto.NewKey().PubKey, 1)
+	assert.That(erin.Verify())
+	assert.That(dave.IsInviterFor(erin))
+}
+
+func TestPseudonymPosition(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+
+	// pseudonym cannot change the position of the member
+	c := try.To1(carol.addBlock(carolKey, Block{
+		InviteePubKey: crypto.NewKey().PubKey,
+		Position:      2,
+		Kind:          KindPseudonym,
+	}))
+	r := c.VerifyReport()
+	assert.Equal(r.Failure, FailRotation)
+	assert.Equal(r.Block, 3)
+}
+
+func TestPseudonymEncoding(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	pseudo := carol.Pseudonym(carolKey, crypto.NewKey().PubKey)
+
+	c := try.To1(ParseChain(pseudo.Bytes()))
+	assert.That(c.Verify())
+	assert.That(c.IsPseudonym())
+
+	d := try.To1(json.Marshal(pseudo))
+	var jc Chain
+	try.To(json.Unmarshal(d, &jc))
+	assert.That(jc.Verify())
+	assert.That(jc.IsPseudonym())
+	assert.Equal(jc.Depth(), 2)
+}
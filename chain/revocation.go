@@ -0,0 +1,299 @@
+package chain
+
+import (
+	"sort"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+// revocationDomain separates the revocation signatures from the block
+// signatures, i.e. a revocation signature cannot be used as anything else.
+const revocationDomain = "ic-revocation-v1"
+
+// Revocation is a signed statement that the invitation block, which hash is
+// BlockHash, isn't valid anymore. Only the inviter or any ancestor of the
+// revoked member can issue an effective revocation. When a member is revoked,
+// everyone invited by it is revoked as well, because their chains include the
+// revoked block.
+//
+// The ancestors are members, not keys: only the current key of the ancestor,
+// i.e. its last rotation in the revoked chain, can revoke. If the ancestor has
+// rotated its key after the invitation, the Rotations link the current key to
+// the RevokerPubKey, see RevokeRotated.
+type Revocation struct {
+	BlockHash     []byte
+	RevokerPubKey crypto.PubKey
+	Signature     crypto.Signature
+
+	// Rotations are the revoker's own rotation blocks which are missing from
+	// the revoked chain. They are signed by the rotated keys, i.e. the
+	// Signature doesn't need to cover them.
+	Rotations []Block
+}
+
+// Revoke returns a revocation of the revoked chain's leaf member signed by the
+// revokerKey. The revokerKey must be the current key of the leaf member's
+// ancestor in the revoked chain.
+func Revoke(revokerKey crypto.KeyHandle, revoked Chain) Revocation {
+	return try.To1(TryRevoke(revokerKey, revoked))
+}
+
+// TryRevoke is error returning version of Revoke. It returns ErrEmptyChain if
+// the revoked chain has no blocks and ErrNotAncestor if the revokerKey cannot
+// revoke the leaf member.
+func TryRevoke(revokerKey crypto.KeyHandle, revoked Chain) (r Revocation, err error) {
+	return TryRevokeRotated(revokerKey, Nil, revoked)
+}
+
+// RevokeRotated is Revoke for the ancestor which has rotated its key after it
+// invited the revoked chain's leaf member, e.g. the device of the old key is
+// lost. The revokerChain is the revoker's own chain, where the revokerKey is
+// the leaf key. Its rotation blocks are added to the revocation to prove that
+// the revokerKey is the successor of the ancestor's key in the revoked chain.
+func RevokeRotated(
+	revokerKey crypto.KeyHandle,
+	revokerChain Chain,
+	revoked Chain,
+) Revocation {
+	return try.To1(TryRevokeRotated(revokerKey, revokerChain, revoked))
+}
+
+// TryRevokeRotated is error returning version of RevokeRotated. It returns
+// ErrEmptyChain if the revoked chain has no blocks and ErrNotAncestor if the
+// revokerKey cannot revoke the leaf member.
+func TryRevokeRotated(
+	revokerKey crypto.KeyHandle,
+	revokerChain Chain,
+	revoked Chain,
+) (r Revocation, err error) {
+	defer err2.Handle(&err)
+
+	if revoked.Len() == 0 {
+		return r, ErrEmptyChain
+	}
+	m := revoked.members()
+	i := m[len(m)-1]
+	r = Revocation{
+		BlockHash:     revoked.Blocks[i].Hash(),
+		RevokerPubKey: revokerKey.PublicKey(),
+	}
+	// the shortest rotation proof first, i.e. no proof
+	rots := revokerChain.Blocks
+	for k := len(rots); ; k-- {
+		if r.Rotations = rots[k:]; len(r.Rotations) == 0 {
+			r.Rotations = nil
+		}
+		if r.revokes(revoked, i) {
+			break
+		}
+		if k == 0 || rots[k-1].Kind != KindRotation {
+			return Revocation{}, ErrNotAncestor
+		}
+	}
+	r.Signature = revokerKey.Sign(r.signingInput())
+	return r, nil
+}
+
+// ParseRevocation decodes the revocation. Decoding errors are ErrDecode.
+func ParseRevocation(d []byte) (r Revocation, err error) {
+	defer err2.Handle(&err)
+
+	dec := newDecoder(d)
+	for dec.more() {
+		tag, v := dec.field()
+		switch tag {
+		case 0: // decoding error is already set
+		case tagRevokedHash:
+			r.BlockHash = clone(v)
+		case tagRevokerPubKey:
+			r.RevokerPubKey = clone(v)
+		case tagRevokerSignature:
+			r.Signature = clone(v)
+		case tagRevokerRotations:
+			r.Rotations = dec.rotations(v)
+		default:
+			dec.fail("unknown tag %d", tag)
+		}
+	}
+	return r, dec.result()
+}
+
+func (r Revocation) Bytes() []byte {
+	return try.To1(r.TryBytes())
+}
+
+// TryBytes is error returning version of Bytes.
+func (r Revocation) TryBytes() (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	enc := newEncoder()
+	enc.bytes(tagRevokedHash, r.BlockHash)
+	enc.bytes(tagRevokerPubKey, r.RevokerPubKey)
+	enc.bytes(tagRevokerSignature, r.Signature)
+	if len(r.Rotations) > 0 {
+		d := try.To1(encodeChain(Chain{Blocks: r.Rotations}))
+		enc.bytes(tagRevokerRotations, d)
+	}
+	return enc.result()
+}
+
+// VerifySign verifies the revocation's signature. Note that it doesn't tell if
+// the revoker is allowed to revoke the block, see Chain.VerifyWith.
+func (r Revocation) VerifySign() bool {
+	return crypto.VerifySign(r.RevokerPubKey, r.signingInput(), r.Signature)
+}
+
+func (r Revocation) signingInput() []byte {
+	return append([]byte(revocationDomain), r.BlockHash...)
+}
+
+// RevocationSet is a set of revocations collected from many sources. Only
+// revocations with valid signatures are added. The zero value is an empty set
+// ready to use.
+type RevocationSet struct {
+	revs map[string][]Revocation // key is BlockHash
+}
+
+// NewRevocationSet returns a set of the revocations. Revocations with invalid
+// signatures are skipped.
+func NewRevocationSet(revs ...Revocation) RevocationSet {
+	var s RevocationSet
+	for _, r := range revs {
+		_ = s.Add(r)
+	}
+	return s
+}
+
+// Add adds the revocation to the set. It returns ErrBadSignature if the
+// revocation's signature doesn't verify. Only the exact duplicates are
+// ignored. The Signature doesn't cover the Rotations, i.e. anyone can make
+// variants of a revocation with broken rotation proofs, and they must not
+// replace the working one.
+func (s *RevocationSet) Add(r Revocation) (err error) {
+	defer err2.Handle(&err)
+
+	if !r.VerifySign() {
+		return ErrBadSignature
+	}
+	if s.revs == nil {
+		s.revs = make(map[string][]Revocation)
+	}
+	key := string(r.BlockHash)
+	d := try.To1(r.TryBytes())
+	for _, old := range s.revs[key] {
+		if crypto.EqualBytes(old.Bytes(), d) {
+			return nil
+		}
+	}
+	s.revs[key] = append(s.revs[key], r)
+	return nil
+}
+
+// Merge adds all the revocations of the other set to s.
+func (s *RevocationSet) Merge(other RevocationSet) {
+	for _, revs := range other.revs {
+		for _, r := range revs {
+			_ = s.Add(r)
+		}
+	}
+}
+
+func (s RevocationSet) Len() (n int) {
+	for _, revs := range s.revs {
+		n += len(revs)
+	}
+	return n
+}
+
+// Revocations returns all of the revocations of the set in a stable order.
+func (s RevocationSet) Revocations() []Revocation {
+	revs := make([]Revocation, 0, s.Len())
+	for _, rs := range s.revs {
+		revs = append(revs, rs...)
+	}
+	sort.Slice(revs, func(i, j int) bool {
+		return string(revs[i].Bytes()) < string(revs[j].Bytes())
+	})
+	return revs
+}
+
+// revoked tells if the chain's block i is revoked by some of its ancestors.
+// Only the invitation blocks can be revoked, the rotation and pseudonym blocks
+// belong to the member before them.
+func (s RevocationSet) revoked(c Chain, i int) bool {
+	if c.Blocks[i].Kind != KindInvite {
+		return false
+	}
+	for _, r := range s.revs[string(c.Blocks[i].Hash())] {
+		if r.revokes(c, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyWith verifies the chain like Verify and checks that none of its blocks
+// are revoked by the revs.
+func (c Chain) VerifyWith(revs RevocationSet) bool {
+	return c.VerifyReportWith(revs).OK()
+}
+
+// TryVerifyWith is error returning version of VerifyWith, see TryVerify. For
+// the revoked blocks the error is ErrRevoked.
+func (c Chain) TryVerifyWith(revs RevocationSet) error {
+	return c.VerifyReportWith(revs).Err()
+}
+
+// VerifyReportWith is VerifyReport which checks the revocations as well.
+func (c Chain) VerifyReportWith(revs RevocationSet) VerifyReport {
+	r := c.VerifyReport()
+	if !r.OK() {
+		return r
+	}
+	for i := 1; i < c.Len(); i++ {
+		if revs.revoked(c, i) {
+			return VerifyReport{Block: i, Failure: FailRevoked}
+		}
+	}
+	return r
+}
+
+// revokes tells if the revoker of r is an ancestor of the member invited in
+// the chain's block i. The revoker must have the current key of the ancestor,
+// or its successor by the Rotations.
+func (r Revocation) revokes(c Chain, i int) bool {
+	for _, m := range c.members() {
+		if m >= i {
+			break
+		}
+		if r.rotatesFrom(c.Blocks[c.currentKeyBlock(m)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// rotatesFrom tells if the Rotations link the key of the block b to the
+// RevokerPubKey.
+func (r Revocation) rotatesFrom(b Block) bool {
+	for _, rot := range r.Rotations {
+		if rot.Kind != KindRotation ||
+			!crypto.EqualBytes(rot.HashToPrev, b.Hash()) ||
+			!rot.validAlgorithm() || !rot.VerifySign(b.InviteePubKey) {
+			return false
+		}
+		b = rot
+	}
+	return crypto.EqualBytes(b.InviteePubKey, r.RevokerPubKey)
+}
+
+// currentKeyBlock returns the index of the block which has the current key of
+// the member invited in the block m, i.e. m or its last rotation block.
+func (c Chain) currentKeyBlock(m int) int {
+	for m+1 < c.Len() && c.Blocks[m+1].Kind == KindRotation {
+		m++
+	}
+	return m
+}
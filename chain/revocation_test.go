@@ -0,0 +1,217 @@
+package chain
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestRevoke(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// root -> bob -> carol -> dave
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	dave := carol.Invite(carolKey, crypto.NewKey().PubKey, 1)
+
+	var revs RevocationSet
+	assert.That(carol.VerifyWith(revs))
+	assert.That(dave.VerifyWith(revs))
+
+	// bob revokes carol, and dave is revoked with her
+	r := Revoke(bob.Key, carol)
+	assert.That(r.VerifySign())
+	try.To(revs.Add(r))
+	assert.ThatNot(carol.VerifyWith(revs))
+	assert.ThatNot(dave.VerifyWith(revs))
+	report := dave.VerifyReportWith(revs)
+	assert.Equal(report.Failure, FailRevoked)
+	assert.Equal(report.Block, 2)
+	assert.That(errors.Is(dave.TryVerifyWith(revs), ErrRevoked))
+
+	// others are not effected
+	assert.That(bob.VerifyWith(revs))
+	assert.That(alice.VerifyWith(revs))
+	assert.That(carol.Verify(), "plain Verify doesn't know revocations")
+}
+
+func TestRevokeByAncestor(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	dave := carol.Invite(carolKey, crypto.NewKey().PubKey, 1)
+
+	// root is an ancestor of dave, but alice isn't
+	revs := NewRevocationSet(Revoke(root.Key, dave))
+	assert.ThatNot(dave.VerifyWith(revs))
+	assert.That(carol.VerifyWith(revs))
+
+	_, err := TryRevoke(alice.Key, dave)
+	assert.That(errors.Is(err, ErrNotAncestor))
+	_, err = TryRevoke(crypto.Key{PubKey: dave.LeafPubKey()}, dave)
+	assert.That(errors.Is(err, ErrNotAncestor), "cannot revoke self")
+	_, err = TryRevoke(root.Key, Nil)
+	assert.That(errors.Is(err, ErrEmptyChain))
+
+	// forged revocation: alice signs a revocation of carol's block
+	forged := Revocation{
+		BlockHash:     carol.lastBlock().Hash(),
+		RevokerPubKey: alice.PubKey,
+	}
+	forged.Signature = alice.Sign(forged.signingInput())
+	revs = NewRevocationSet(forged)
+	assert.Equal(revs.Len(), 1)
+	assert.That(carol.VerifyWith(revs), "alice isn't carol's ancestor")
+
+	// revocation is for the member, not for its latest key
+	rotated := carol.Rotate(carolKey, crypto.NewKey().PubKey)
+	revs = NewRevocationSet(Revoke(bob.Key, rotated))
+	assert.ThatNot(carol.VerifyWith(revs))
+	assert.ThatNot(rotated.VerifyWith(revs))
+}
+
+func TestRevokeRotated(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// bob invites carol, rotates his key, and then invites dave
+	bobKey := crypto.NewKey()
+	bobChain := root.Invite(root.Key, bobKey.PubKey, 1)
+	carol := bobChain.Invite(bobKey, crypto.NewKey().PubKey, 1)
+	newKey := crypto.NewKey()
+	rotated := bobChain.Rotate(bobKey, newKey.PubKey)
+	dave := rotated.Invite(newKey, crypto.NewKey().PubKey, 1)
+
+	// the rotated away key cannot revoke the invitees of the new key
+	_, err := TryRevoke(bobKey, dave)
+	assert.That(errors.Is(err, ErrNotAncestor))
+	forged := Revocation{
+		BlockHash:     dave.lastBlock().Hash(),
+		RevokerPubKey: bobKey.PubKey,
+	}
+	forged.Signature = bobKey.Sign(forged.signingInput())
+	assert.That(dave.VerifyWith(NewRevocationSet(forged)))
+	revs := NewRevocationSet(Revoke(newKey, dave))
+	assert.ThatNot(dave.VerifyWith(revs))
+
+	// the new key revokes the invitees of the old key with the rotation proof
+	_, err = TryRevoke(newKey, carol)
+	assert.That(errors.Is(err, ErrNotAncestor))
+	r := RevokeRotated(newKey, rotated, carol)
+	assert.SLen(r.Rotations, 1)
+	revs = NewRevocationSet(r)
+	assert.ThatNot(carol.VerifyWith(revs))
+	assert.That(bobChain.VerifyWith(revs))
+	assert.DeepEqual(try.To1(ParseRevocation(r.Bytes())), r)
+
+	// the proof is needed only for the missing rotations
+	r = RevokeRotated(newKey, rotated, dave)
+	assert.SLen(r.Rotations, 0)
+
+	// the proof must be signed by the rotated key
+	otherKey := crypto.NewKey()
+	other := bobChain.Rotate(bobKey, otherKey.PubKey)
+	r = RevokeRotated(otherKey, other, carol)
+	r.Rotations[0].InviteePubKey = newKey.PubKey
+	r.RevokerPubKey = newKey.PubKey
+	r.Signature = newKey.Sign(r.signingInput())
+	assert.That(carol.VerifyWith(NewRevocationSet(r)))
+	_, err = TryRevokeRotated(newKey, other, carol)
+	assert.That(errors.Is(err, ErrNotAncestor))
+
+	// the variant without the proof doesn't hide the genuine revocation
+	genuine := RevokeRotated(newKey, rotated, carol)
+	stripped := genuine
+	stripped.Rotations = nil
+	revs = NewRevocationSet(stripped, genuine)
+	assert.Equal(revs.Len(), 2)
+	assert.ThatNot(carol.VerifyWith(revs))
+	revs = NewRevocationSet(genuine, stripped, genuine)
+	assert.Equal(revs.Len(), 2)
+	assert.ThatNot(carol.VerifyWith(revs))
+}
+
+func TestRevokeMalleated(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// P-256 root -> P-256 gina, and the root revokes gina
+	rootKey := try.To1(crypto.GenerateKey(crypto.P256))
+	gina := NewRootChain(rootKey.PubKey).
+		Invite(rootKey, try.To1(crypto.GenerateKey(crypto.P256)).PubKey, 1)
+	revs := NewRevocationSet(Revoke(rootKey, gina))
+	assert.ThatNot(gina.VerifyWith(revs))
+
+	// (r, n-s) would be valid ECDSA signature with other block hash, which
+	// would escape the revocation
+	var sig struct{ R, S *big.Int }
+	leaf := &gina.Blocks[1]
+	try.To1(asn1.Unmarshal(leaf.InvitersSignature, &sig))
+	sig.S.Sub(elliptic.P256().Params().N, sig.S)
+	leaf.InvitersSignature = try.To1(asn1.Marshal(sig))
+	assert.ThatNot(gina.VerifyWith(revs))
+	assert.Equal(gina.VerifyReport().Failure, FailSignature)
+}
+
+func TestRevokeNotMember(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// revocations of the rotation and pseudonym blocks have no effect
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	rotated := carol.Rotate(carolKey, crypto.NewKey().PubKey)
+	for _, c := range []Chain{
+		rotated,
+		carol.Pseudonym(carolKey, crypto.NewKey().PubKey),
+	} {
+		r := Revocation{
+			BlockHash:     c.lastBlock().Hash(),
+			RevokerPubKey: bob.PubKey,
+		}
+		r.Signature = bob.Sign(r.signingInput())
+		assert.That(c.VerifyWith(NewRevocationSet(r)))
+	}
+}
+
+func TestRevocationSet(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carol := bob.Invite(bob.Key, crypto.NewKey().PubKey, 1)
+	r1 := Revoke(bob.Key, carol)
+	r2 := Revoke(root.Key, carol)
+	r3 := Revoke(root.Key, alice.Chain)
+
+	s1 := NewRevocationSet(r1, r1)
+	assert.Equal(s1.Len(), 1)
+	s2 := NewRevocationSet(r2, r3)
+	s1.Merge(s2)
+	assert.Equal(s1.Len(), 3)
+	assert.SLen(s1.Revocations(), 3)
+
+	bad := r1
+	bad.Signature = crypto.RandSlice(64)
+	assert.That(errors.Is(s1.Add(bad), ErrBadSignature))
+	assert.Equal(NewRevocationSet(bad).Len(), 0)
+}
+
+func TestRevocationEncoding(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	r := Revoke(root.Key, bob.Chain)
+	r2 := try.To1(ParseRevocation(r.Bytes()))
+	assert.DeepEqual(r2, r)
+	assert.That(r2.VerifySign())
+
+	_, err := ParseRevocation(alice.lastBlock().Bytes()[:10])
+	assert.That(errors.Is(err, ErrDecode))
+
+	empty := try.To1(encodeChain(Nil))
+	d := append(r.Bytes(), tagRevokerRotations, 0, byte(len(empty)))
+	_, err = ParseRevocation(append(d, empty...))
+	assert.That(errors.Is(err, ErrDecode), "empty rotations")
+}
@@ -0,0 +1,112 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+// Kind is the type of the Block.
+type Kind int
+
+const (
+	// KindInvite is a block where the inviter adds a new member, i.e. the
+	// chain gets one level deeper. Root block is KindInvite as well.
+	KindInvite Kind = iota
+
+	// KindRotation is a block where the current leaf key signs its successor
+	// key. The member and its depth in the chain stay the same.
+	KindRotation
+
+	// KindPseudonym is a block where the leaf key signs a pseudonym key for a
+	// single relationship, see Chain.Pseudonym. Like with rotations, the
+	// member and its depth stay the same.
+	KindPseudonym
+)
+
+var kindNames = map[Kind]string{
+	KindInvite:    "invite",
+	KindRotation:  "rotation",
+	KindPseudonym: "pseudonym",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+func (k Kind) valid() bool {
+	_, ok := kindNames[k]
+	return ok
+}
+
+func (k Kind) MarshalText() ([]byte, error) {
+	if !k.valid() {
+		return nil, fmt.Errorf("%w: unknown block kind %d", ErrDecode, k)
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *Kind) UnmarshalText(d []byte) error {
+	for kind, name := range kindNames {
+		if name == string(d) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown block kind %q", ErrDecode, d)
+}
+
+// Rotate is called for the chain holder's own chain when its leaf key is
+// changed, e.g. a device is lost. The current leaf key signs the newPubKey and
+// a new chain is returned. The rotated chain has the same members at the same
+// depths, i.e. Hops, CommonInviter and IsInviterFor give the same results as
+// for the original chain.
+func (c Chain) Rotate(
+	currentKey crypto.KeyHandle,
+	newPubKey crypto.PubKey,
+) (nc Chain) {
+	return try.To1(c.TryRotate(currentKey, newPubKey))
+}
+
+// TryRotate is error returning version of Rotate. It returns ErrEmptyChain if
+// the chain has no blocks, ErrNotLeaf if currentKey isn't the leaf key of the
+// chain, and ErrAlgorithm if the newPubKey is malformed.
+func (c Chain) TryRotate(
+	currentKey crypto.KeyHandle,
+	newPubKey crypto.PubKey,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	if c.Len() == 0 {
+		return Nil, ErrEmptyChain
+	}
+	return c.addBlock(currentKey, Block{
+		InviteePubKey: newPubKey,
+		Position:      c.lastBlock().Position,
+		Kind:          KindRotation,
+	})
+}
+
+// Depth returns the leaf member's distance from the root. Rotation blocks
+// aren't counted.
+func (c Chain) Depth() int {
+	return len(c.members()) - 1
+}
+
+// members returns the indexes of the blocks where the chain's members are
+// invited, i.e. the root and the KindInvite blocks. Rotation and pseudonym
+// blocks belong to the member before them.
+func (c Chain) members() []int {
+	m := make([]int, 0, c.Len())
+	for i, b := range c.Blocks {
+		if b.Kind == KindInvite {
+			m = append(m, i)
+		}
+	}
+	return m
+}
@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestRotate(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carolKey := crypto.NewKey()
+	carol := bob.Invite(bob.Key, carolKey.PubKey, 1)
+	assert.Equal(carol.Depth(), 2)
+
+	newKey := crypto.NewKey()
+	rotated := carol.Rotate(carolKey, newKey.PubKey)
+	assert.That(rotated.Verify())
+	assert.SLen(rotated.Blocks, 4)
+	assert.Equal(rotated.Depth(), 2)
+	assert.Equal(rotated.lastBlock().Kind, KindRotation)
+	assert.DeepEqual(rotated.LeafPubKey(), newKey.PubKey)
+
+	// old key cannot rotate or invite anymore
+	_, err := rotated.TryRotate(carolKey, crypto.NewKey().PubKey)
+	assert.That(errors.Is(err, ErrNotLeaf))
+	_, err = rotated.TryInvite(carolKey, crypto.NewKey().PubKey, 1)
+	assert.That(errors.Is(err, ErrNotLeaf))
+	_, err = Nil.TryRotate(carolKey, crypto.NewKey().PubKey)
+	assert.That(errors.Is(err, ErrEmptyChain))
+
+	// rotated chain is the same member at the same depth
+	h1, l1 := alice.Hops(carol)
+	h2, l2 := alice.Hops(rotated)
+	assert.Equal(h1, h2)
+	assert.Equal(l1, l2)
+	assert.Equal(CommonInviter(bob.Chain, rotated), CommonInviter(bob.Chain, carol))
+	assert.That(bob.IsInviterFor(rotated))
+	assert.That(SameInviter(rotated, carol))
+	assert.That(SameRoot(rotated, carol))
+}
+
+func TestRotatedInviter(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	daveKey := crypto.NewKey()
+	dave := bob.Invite(bob.Key, daveKey.PubKey, 1)
+	erin := dave.Invite(daveKey, crypto.NewKey().PubKey, 1)
+
+	// dave loses his device and rotates, invitees before and after rotation
+	// have the same inviter
+	newKey := crypto.NewKey()
+	dave = dave.Rotate(daveKey, newKey.PubKey)
+	frank := dave.Invite(newKey, crypto.NewKey().PubKey, 1)
+	assert.That(frank.Verify())
+	assert.Equal(frank.Depth(), 3)
+
+	assert.That(dave.IsInviterFor(erin))
+	assert.That(dave.IsInviterFor(frank))
+	assert.That(SameInviter(erin, frank))
+	assert.Equal(CommonInviter(erin, frank), 2)
+	h, l := erin.Hops(frank)
+	assert.Equal(h, 2)
+	assert.Equal(l, 2)
+
+	// rotating twice is fine, and so is the root rotation
+	newKey2 := crypto.NewKey()
+	dave = dave.Rotate(newKey, newKey2.PubKey)
+	assert.That(dave.IsInviterFor(frank))
+	rootKey2 := crypto.NewKey()
+	r := root.Rotate(root.Key, rootKey2.PubKey)
+	assert.That(r.Verify())
+	assert.Equal(r.Depth(), 0)
+	gina := r.Invite(rootKey2, crypto.NewKey().PubKey, 1)
+	assert.Equal(CommonInviter(gina, alice.Chain), 0)
+	h, _ = gina.Hops(alice.Chain)
+	assert.Equal(h, 2)
+}
+
+func TestVerifyRotation(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c := alice.Rotate(alice.Key, crypto.NewKey().PubKey)
+	c.Blocks[2].Position++
+	assert.Equal(c.VerifyReport().Failure, FailRotation)
+	assert.That(errors.Is(c.TryVerify(), ErrRotation))
+
+	c = alice.Clone()
+	c.Blocks[1].Kind = Kind(7)
+	assert.Equal(c.VerifyReport().Failure, FailRotation)
+
+	c = alice.Clone()
+	c.Blocks[0].Kind = KindRotation
+	assert.Equal(c.VerifyReport().Failure, FailRoot)
+}
+
+func TestRotationEncoding(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c := alice.Rotate(alice.Key, crypto.NewKey().PubKey)
+	c2 := try.To1(ParseChain(c.Bytes()))
+	assert.Equal(c2.lastBlock().Kind, KindRotation)
+	assert.That(c2.Verify())
+
+	d := try.To1(json.Marshal(c))
+	var c3 Chain
+	try.To(json.Unmarshal(d, &c3))
+	assert.That(c3.Verify())
+	assert.DeepEqual(c3.Bytes(), c.Bytes())
+}
@@ -0,0 +1,116 @@
+package chain
+
+import (
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+// Validity is the optional validity window of the invitation. Zero times mean
+// no limit. Times are stored with one second precision, and the Unix epoch
+// itself cannot be used because it's the zero value of the encoding.
+type Validity struct {
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// ValidFor returns a validity window that starts now and lasts d.
+func ValidFor(d time.Duration) Validity {
+	now := time.Now()
+	return Validity{NotBefore: now, NotAfter: now.Add(d)}
+}
+
+// ValidAt tells if the t is inside the validity window.
+func (v Validity) ValidAt(t time.Time) bool {
+	if !v.NotBefore.IsZero() && t.Before(v.NotBefore) {
+		return false
+	}
+	if !v.NotAfter.IsZero() && t.After(v.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Unlimited tells if the window has no limits.
+func (v Validity) Unlimited() bool {
+	return v.NotBefore.IsZero() && v.NotAfter.IsZero()
+}
+
+// truncate returns the window with the precision of the encoding.
+func (v Validity) truncate() Validity {
+	return Validity{
+		NotBefore: fromUnix(toUnix(v.NotBefore)),
+		NotAfter:  fromUnix(toUnix(v.NotAfter)),
+	}
+}
+
+// InviteValid is like Invite but the invitation is valid only inside the
+// validity window. The window is signed by the inviter, and VerifyAt enforces
+// it for every block in the chain. When the invitation expires, everyone
+// invited by the invitee is expired as well.
+func (c Chain) InviteValid(
+	invitersKey crypto.KeyHandle,
+	inviteesPubKey crypto.PubKey,
+	position Position,
+	validity Validity,
+) (nc Chain) {
+	return try.To1(c.TryInviteValid(invitersKey, inviteesPubKey, position, validity))
+}
+
+// TryInviteValid is error returning version of InviteValid, see TryInvite.
+func (c Chain) TryInviteValid(
+	invitersKey crypto.KeyHandle,
+	inviteesPubKey crypto.PubKey,
+	position Position,
+	validity Validity,
+) (nc Chain, err error) {
+	defer err2.Handle(&err)
+
+	return c.addBlock(invitersKey, Block{
+		InviteePubKey: inviteesPubKey,
+		Position:      position,
+		Validity:      validity.truncate(),
+	})
+}
+
+// VerifyAt verifies the chain like Verify and checks that all of its blocks are
+// valid at the time t. Note that Verify doesn't check the validity windows.
+func (c Chain) VerifyAt(t time.Time) bool {
+	return c.VerifyReportAt(t).OK()
+}
+
+// TryVerifyAt is error returning version of VerifyAt, see TryVerify. For the
+// blocks which aren't valid at the time t, the error is ErrExpired.
+func (c Chain) TryVerifyAt(t time.Time) error {
+	return c.VerifyReportAt(t).Err()
+}
+
+// VerifyReportAt is VerifyReport which checks the validity windows as well.
+func (c Chain) VerifyReportAt(t time.Time) VerifyReport {
+	r := c.VerifyReport()
+	if !r.OK() {
+		return r
+	}
+	for i, b := range c.Blocks {
+		if !b.ValidAt(t) {
+			return VerifyReport{Block: i, Failure: FailExpired}
+		}
+	}
+	return r
+}
+
+func toUnix(t time.Time) int {
+	if t.IsZero() {
+		return 0
+	}
+	return int(t.Unix())
+}
+
+func fromUnix(sec int) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(sec), 0).UTC()
+}
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestInviteValid(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	now := time.Now()
+	carolKey := crypto.NewKey()
+	carol := bob.InviteValid(bob.Key, carolKey.PubKey, 1, Validity{
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(time.Hour),
+	})
+	assert.That(carol.Verify())
+	assert.That(carol.VerifyAt(now))
+	assert.ThatNot(carol.VerifyAt(now.Add(2 * time.Hour)))
+	assert.ThatNot(carol.VerifyAt(now.Add(-2 * time.Hour)))
+	r := carol.VerifyReportAt(now.Add(2 * time.Hour))
+	assert.Equal(r.Failure, FailExpired)
+	assert.Equal(r.Block, 2)
+	assert.That(errors.Is(carol.TryVerifyAt(now.Add(2*time.Hour)), ErrExpired))
+
+	// carol's invitees expire with her
+	dave := carol.Invite(carolKey, crypto.NewKey().PubKey, 1)
+	assert.That(dave.VerifyAt(now))
+	assert.ThatNot(dave.VerifyAt(now.Add(2 * time.Hour)))
+
+	// chains without windows are valid forever
+	assert.That(bob.VerifyAt(now.Add(100 * 365 * 24 * time.Hour)))
+}
+
+func TestValiditySigned(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	carol := bob.InviteValid(bob.Key, crypto.NewKey().PubKey, 1,
+		ValidFor(time.Hour))
+	carol.Blocks[2].NotAfter = carol.Blocks[2].NotAfter.Add(time.Hour)
+	assert.Equal(carol.VerifyReport().Failure, FailSignature,
+		"inviter's signature covers the window")
+
+	c := alice.Clone()
+	c.Blocks[0].NotAfter = time.Now()
+	assert.Equal(c.VerifyReport().Failure, FailRoot)
+}
+
+func TestValidityEncoding(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	notAfter := time.Date(2030, 1, 2, 3, 4, 5, 6, time.Local)
+	c := alice.InviteValid(alice.Key, crypto.NewKey().PubKey, 1,
+		Validity{NotAfter: notAfter})
+	b := c.lastBlock()
+	assert.That(b.NotBefore.IsZero())
+	assert.That(b.NotAfter.Equal(notAfter.Truncate(time.Second)))
+
+	c2 := try.To1(ParseChain(c.Bytes()))
+	assert.That(EqualBlocks(c2.lastBlock(), b))
+	assert.That(c2.Verify())
+
+	var c3 Chain
+	try.To(json.Unmarshal(try.To1(json.Marshal(c)), &c3))
+	assert.That(c3.Verify())
+	assert.DeepEqual(c3.Bytes(), c.Bytes())
+}
@@ -0,0 +1,160 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/lainio/ic/crypto"
+)
+
+// Failure tells which verification rule failed.
+type Failure int
+
+const (
+	// FailNone means that the chain is valid.
+	FailNone Failure = iota
+
+	// FailEmpty means that the chain has no blocks at all.
+	FailEmpty
+
+	// FailRoot means that the root block isn't root shaped, i.e. it has
+	// HashToPrev, InvitersSignature, AcceptanceSignature or Validity set, or
+	// it isn't KindInvite.
+	FailRoot
+
+	// FailHashLink means that the block's HashToPrev doesn't match to the hash
+	// of the previous block. Typically the block is spliced from other chain
+	// or the blocks are reordered.
+	FailHashLink
+
+	// FailSignature means that the block isn't signed by the previous block's
+	// InviteePubKey.
+	FailSignature
+
+	// FailRotation means that the block's Kind is unknown or the rotation or
+	// pseudonym block changes the Position of the member.
+	FailRotation
+
+	// FailRevoked means that the block is revoked by the inviter or some other
+	// ancestor, see Chain.VerifyWith.
+	FailRevoked
+
+	// FailExpired means that the block isn't valid at the verification time,
+	// see Chain.VerifyAt.
+	FailExpired
+
+	// FailPosition means that the invitation block gives better Position than
+	// the inviter has, or the root isn't at RootPosition.
+	FailPosition
+
+	// FailAcceptance means that the block's AcceptanceSignature doesn't verify
+	// with its InviteePubKey, see Offer.
+	FailAcceptance
+
+	// FailAlgorithm means that the block's Algorithm is unknown or it isn't
+	// the algorithm of the block's InviteePubKey.
+	FailAlgorithm
+)
+
+var failureErrs = map[Failure]error{
+	FailEmpty:      ErrEmptyChain,
+	FailRoot:       ErrBadRoot,
+	FailHashLink:   ErrHashLink,
+	FailSignature:  ErrBadSignature,
+	FailRotation:   ErrRotation,
+	FailRevoked:    ErrRevoked,
+	FailExpired:    ErrExpired,
+	FailPosition:   ErrPosition,
+	FailAcceptance: ErrAcceptance,
+	FailAlgorithm:  ErrAlgorithm,
+}
+
+func (f Failure) String() string {
+	if f == FailNone {
+		return "ok"
+	}
+	return failureErrs[f].Error()
+}
+
+// VerifyReport describes the result of the chain verification. If the chain
+// isn't valid, Block is the index of the first failing block and Failure tells
+// why it failed.
+type VerifyReport struct {
+	Block   int
+	Failure Failure
+}
+
+// OK tells if the chain was valid.
+func (r VerifyReport) OK() bool {
+	return r.Failure == FailNone
+}
+
+// Err returns nil if the chain was valid. If not the returned error wraps the
+// sentinel error of the Failure.
+func (r VerifyReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return fmt.Errorf("%w: block %d", failureErrs[r.Failure], r.Block)
+}
+
+func (r VerifyReport) String() string {
+	if r.OK() {
+		return r.Failure.String()
+	}
+	return r.Err().Error()
+}
+
+// VerifyReport verifies the whole chain and reports the first failure. Every
+// block after the root must link to the hash of the previous block and be
+// signed by the previous block's InviteePubKey with the previous block's
+// Algorithm. Acceptance signatures are checked if the blocks have them.
+func (c Chain) VerifyReport() VerifyReport {
+	if c.Len() == 0 {
+		return VerifyReport{Block: 0, Failure: FailEmpty}
+	}
+	root := c.firstBlock()
+	if len(root.HashToPrev) != 0 || len(root.InvitersSignature) != 0 ||
+		root.Kind != KindInvite || !root.Validity.Unlimited() ||
+		len(root.AcceptanceSignature) != 0 {
+		return VerifyReport{Block: 0, Failure: FailRoot}
+	}
+	if root.Position != RootPosition {
+		return VerifyReport{Block: 0, Failure: FailPosition}
+	}
+	if !root.validAlgorithm() {
+		return VerifyReport{Block: 0, Failure: FailAlgorithm}
+	}
+
+	var invitersPubKey crypto.PubKey
+	// start with the root key
+	invitersPubKey = root.InviteePubKey
+	prevHash := root.Hash()
+	prevPosition := root.Position
+
+	for i, b := range c.Blocks[1:] {
+		if !b.Kind.valid() ||
+			b.Kind != KindInvite && b.Position != prevPosition {
+			return VerifyReport{Block: i + 1, Failure: FailRotation}
+		}
+		if !prevPosition.CanInvite(b.Position) {
+			return VerifyReport{Block: i + 1, Failure: FailPosition}
+		}
+		if !crypto.EqualBytes(b.HashToPrev, prevHash) {
+			return VerifyReport{Block: i + 1, Failure: FailHashLink}
+		}
+		if !b.validAlgorithm() {
+			return VerifyReport{Block: i + 1, Failure: FailAlgorithm}
+		}
+		if !b.VerifySign(invitersPubKey) {
+			return VerifyReport{Block: i + 1, Failure: FailSignature}
+		}
+		if len(b.AcceptanceSignature) != 0 && !b.VerifyAcceptance() {
+			return VerifyReport{Block: i + 1, Failure: FailAcceptance}
+		}
+		// the next block is signed with this block's pub key
+		invitersPubKey = b.InviteePubKey
+		prevHash = b.Hash()
+		prevPosition = b.Position
+	}
+	return VerifyReport{Block: NotConnected, Failure: FailNone}
+}
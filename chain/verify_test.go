@@ -0,0 +1,126 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestVerifyReport(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	r := alice.VerifyReport()
+	assert.That(r.OK())
+	assert.NoError(r.Err())
+	assert.Equal(r.Failure, FailNone)
+
+	r = Nil.VerifyReport()
+	assert.Equal(r.Failure, FailEmpty)
+	assert.That(errors.Is(r.Err(), ErrEmptyChain))
+
+	c := alice.Clone()
+	c.Blocks[1].InvitersSignature[0] += 0x01
+	r = c.VerifyReport()
+	assert.Equal(r.Failure, FailSignature)
+	assert.Equal(r.Block, 1)
+	assert.That(errors.Is(r.Err(), ErrBadSignature))
+}
+
+func TestVerifyRootShape(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c := alice.Clone()
+	c.Blocks[0].HashToPrev = crypto.RandSlice(32)
+	r := c.VerifyReport()
+	assert.Equal(r.Failure, FailRoot)
+	assert.Equal(r.Block, 0)
+	assert.That(errors.Is(c.TryVerify(), ErrBadRoot))
+
+	c = alice.Clone()
+	c.Blocks[0].InvitersSignature = crypto.RandSlice(64)
+	assert.Equal(c.VerifyReport().Failure, FailRoot)
+}
+
+// TestVerifySplicedChain tests that a block signed by a correct key but taken
+// from other chain is detected. Dave is member of two webs-of-trust with the
+// same key, and the block of his invitee is moved to his other chain.
+func TestVerifySplicedChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dave := crypto.NewKey()
+	root2 := crypto.NewKey()
+	dave1 := root.Invite(root.Key, dave.PubKey, 1)
+	dave2 := NewRootChain(root2.PubKey).Invite(root2, dave.PubKey, 1)
+
+	erin := dave1.Invite(dave, crypto.NewKey().PubKey, 1)
+	assert.That(erin.Verify())
+
+	spliced := dave2.Clone()
+	spliced.Blocks = append(spliced.Blocks, erin.lastBlock())
+	assert.That(spliced.lastBlock().VerifySign(dave.PubKey),
+		"signature alone is valid")
+
+	r := spliced.VerifyReport()
+	assert.Equal(r.Failure, FailHashLink)
+	assert.Equal(r.Block, 2)
+	assert.That(errors.Is(spliced.TryVerify(), ErrHashLink))
+	assert.ThatNot(spliced.Verify())
+}
+
+func TestVerifyReorderedChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	bobKey := crypto.NewKey()
+	c := alice.Invite(alice.Key, bobKey.PubKey, 1)
+	c = c.Invite(bobKey, crypto.NewKey().PubKey, 1)
+	assert.That(c.Verify())
+
+	c.Blocks[2], c.Blocks[3] = c.Blocks[3], c.Blocks[2]
+	r := c.VerifyReport()
+	assert.Equal(r.Failure, FailHashLink)
+	assert.Equal(r.Block, 2)
+}
+
+func TestVerifyAlgorithms(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// P-256 root invites ed25519 member which invites P-256 member
+	rootKey := try.To1(crypto.GenerateKey(crypto.P256))
+	erinKey := crypto.NewKey()
+	frankKey := try.To1(crypto.GenerateKey(crypto.P256))
+	c := NewRootChain(rootKey.PubKey).
+		Invite(rootKey.Handle(), erinKey.PubKey, 1).
+		Invite(erinKey, frankKey.PubKey, 1)
+	assert.That(c.Verify())
+	assert.Equal(c.Blocks[0].Algorithm, crypto.P256)
+	assert.Equal(c.Blocks[1].Algorithm, crypto.Ed25519)
+	assert.Equal(c.Blocks[2].Algorithm, crypto.P256)
+	assert.That(c.Rotate(frankKey, crypto.NewKey().PubKey).Verify())
+
+	// malformed keys aren't signed
+	_, err := c.TryInvite(frankKey, frankKey.PubKey[1:], 1)
+	assert.That(errors.Is(err, ErrAlgorithm))
+	_, err = c.TryRotate(frankKey, []byte{0x7f, 1, 2})
+	assert.That(errors.Is(err, ErrAlgorithm))
+
+	// algorithm is signed by the inviter
+	broken := c.Clone()
+	broken.Blocks[2].Algorithm = crypto.Ed25519
+	r := broken.VerifyReport()
+	assert.Equal(r.Failure, FailAlgorithm)
+	assert.Equal(r.Block, 2)
+	assert.That(errors.Is(r.Err(), ErrAlgorithm))
+
+	broken = c.Clone()
+	broken.Blocks[0].Algorithm = crypto.Algorithm(99)
+	assert.Equal(broken.VerifyReport().Failure, FailAlgorithm)
+
+	// P-256 key cannot sign as ed25519 key and vice versa
+	broken = c.Clone()
+	broken.Blocks[1].InvitersSignature = erinKey.Sign(
+		broken.Blocks[1].ExcludeSign().Bytes())
+	assert.Equal(broken.VerifyReport().Failure, FailSignature)
+}
@@ -0,0 +1,68 @@
+// Package challenge implements a replay-safe challenge-response protocol for
+// proving that the party presenting an invitation chain holds the chain's leaf
+// key. The verifier issues a Challenge carrying a random nonce, the verifier's
+// identity (audience), the challenged leaf key and an expiry time. The chain
+// holder signs it with a Responder, and the Verifier accepts every nonce only
+// once.
+//
+// Signatures are domain separated, i.e. they cannot be confused with block
+// signatures or used for any other purpose.
+package challenge
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/lainio/ic/chain"
+)
+
+const (
+	domain   = "ic-challenge-v1"
+	nonceLen = 32
+)
+
+var (
+	ErrExpired      = errors.New("challenge expired")
+	ErrUnknownNonce = errors.New("unknown nonce")
+	ErrReplay       = errors.New("nonce already used")
+	ErrAudience     = errors.New("challenge is for other verifier")
+	ErrSubject      = errors.New("challenge is for other key")
+	ErrBadSignature = errors.New("bad signature")
+	ErrMalformed    = errors.New("malformed challenge")
+	ErrChain        = errors.New("chain doesn't verify")
+)
+
+// Challenge is the message the verifier sends to the chain holder.
+type Challenge struct {
+	Nonce    chain.Base64 `json:"nonce"`
+	Verifier chain.Base64 `json:"verifier"`
+	Subject  chain.Base64 `json:"subject"`
+	Expires  time.Time    `json:"expires"`
+}
+
+// Response is the chain holder's answer to the Challenge.
+type Response struct {
+	Challenge Challenge    `json:"challenge"`
+	Signature chain.Base64 `json:"signature"`
+}
+
+// wellFormed checks that all the fields are set.
+func (c Challenge) wellFormed() error {
+	if len(c.Nonce) != nonceLen || len(c.Verifier) == 0 ||
+		len(c.Subject) == 0 || c.Expires.IsZero() {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// SigningInput returns the bytes the chain holder signs: the domain string and
+// the length prefixed fields, and the expiry as Unix seconds.
+func (c Challenge) SigningInput() []byte {
+	d := []byte(domain)
+	for _, f := range [][]byte{c.Nonce, c.Verifier, c.Subject} {
+		d = binary.BigEndian.AppendUint16(d, uint16(len(f)))
+		d = append(d, f...)
+	}
+	return binary.BigEndian.AppendUint64(d, uint64(c.Expires.Unix()))
+}
@@ -0,0 +1,103 @@
+package challenge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+var (
+	rootKey, aliceKey, bobKey, verifierKey crypto.Key
+	aliceChain, bobChain                   chain.Chain
+
+	// expiredChain is alice's invitation which expired an hour ago
+	expiredChain chain.Chain
+)
+
+func init() {
+	rootKey = crypto.NewKey()
+	aliceKey = crypto.NewKey()
+	bobKey = crypto.NewKey()
+	verifierKey = crypto.NewKey()
+
+	root := chain.NewRootChain(rootKey.PubKey)
+	aliceChain = root.Invite(rootKey, aliceKey.PubKey, 1)
+	bobChain = root.Invite(rootKey, bobKey.PubKey, 1)
+	now := time.Now()
+	expiredChain = root.InviteValid(rootKey, aliceKey.PubKey, 1,
+		chain.Validity{
+			NotBefore: now.Add(-2 * time.Hour),
+			NotAfter:  now.Add(-time.Hour),
+		})
+}
+
+func TestChallengeResponse(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, 0)
+	alice := try.To1(NewResponder(aliceKey.Handle(), aliceChain))
+
+	ch := try.To1(v.New(alice.Chain()))
+	assert.SLen(ch.Nonce, nonceLen)
+	assert.DeepEqual(crypto.PubKey(ch.Subject), aliceKey.PubKey)
+
+	r := try.To1(alice.Respond(ch, verifierKey.PubKey))
+	assert.NoError(v.Verify(alice.Chain(), r))
+}
+
+func TestNewResponder(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	_, err := NewResponder(bobKey, aliceChain)
+	assert.That(errors.Is(err, chain.ErrNotLeaf))
+	_, err = NewResponder(bobKey, chain.Nil)
+	assert.That(errors.Is(err, chain.ErrEmptyChain))
+}
+
+func TestResponderRefuses(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, 0)
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+	ch := try.To1(v.New(aliceChain))
+
+	// wrong audience, i.e. someone relays verifier's challenge to alice
+	_, err := alice.Respond(ch, crypto.NewKey().PubKey)
+	assert.That(errors.Is(err, ErrAudience))
+
+	// challenge for bob's key
+	bobCh := try.To1(v.New(bobChain))
+	_, err = alice.Respond(bobCh, verifierKey.PubKey)
+	assert.That(errors.Is(err, ErrSubject))
+
+	// malformed, e.g. trying to get alice sign arbitrary data
+	bad := ch
+	bad.Nonce = []byte("sign this")
+	_, err = alice.Respond(bad, verifierKey.PubKey)
+	assert.That(errors.Is(err, ErrMalformed))
+
+	alice.now = func() time.Time { return ch.Expires.Add(time.Second) }
+	_, err = alice.Respond(ch, verifierKey.PubKey)
+	assert.That(errors.Is(err, ErrExpired))
+}
+
+func TestSigningInput(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	ch := Challenge{
+		Nonce:    make([]byte, nonceLen),
+		Verifier: []byte{0x01},
+		Subject:  []byte{0x02, 0x03},
+		Expires:  time.Unix(0x0102, 0),
+	}
+	want := append([]byte(domain), 0x00, 0x20)
+	want = append(want, make([]byte, nonceLen)...)
+	want = append(want, 0x00, 0x01, 0x01, 0x00, 0x02, 0x02, 0x03)
+	want = append(want, 0, 0, 0, 0, 0, 0, 0x01, 0x02)
+	assert.DeepEqual(ch.SigningInput(), want)
+}
@@ -0,0 +1,332 @@
+package challenge
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// PIN-bound challenges
+//
+// The verifier shows or sends a random PIN over other, safe channel, e.g. it's
+// read aloud or shown on the screen. The chain holder proves that it has both
+// the leaf key and the PIN. The construction prevents offline brute-forcing of
+// the PIN from a captured transcript:
+//
+//  1. The verifier sends the challenge with an ephemeral X25519 key signed by
+//     its identity key.
+//  2. The responder checks the signature against the verifier it expects to
+//     talk to, creates its own ephemeral key, and derives the MAC key from the
+//     Diffie-Hellman secret and the PIN with HKDF.
+//  3. The responder signs the transcript with its leaf key and MACs it.
+//
+// A passive eavesdropper doesn't know the DH secret, and an active attacker
+// cannot replace the verifier's ephemeral key because it's signed. Online
+// guessing is limited by per-key failure rate limiting, and every challenge has
+// a new PIN.
+
+const (
+	pinDomain         = "ic-pin-v1"
+	pinVerifierDomain = "ic-pin-verifier-v1"
+)
+
+var (
+	ErrPIN         = errors.New("wrong PIN")
+	ErrRateLimited = errors.New("too many failed attempts")
+	ErrPINConfig   = errors.New("bad PIN configuration")
+)
+
+// PINConfig configures the PIN-bound challenges. The zero values are replaced
+// with the defaults.
+type PINConfig struct {
+	// Length is the number of PIN digits: 4-12, default 6.
+	Length int
+
+	// MaxFailures is the number of failed attempts per chain holder key in the
+	// Window before new challenges are refused, default 3.
+	MaxFailures int
+
+	// Window is the rate limiting window, default 15 minutes.
+	Window time.Duration
+
+	// TTL is the lifetime of the challenges, default DefaultTTL.
+	TTL time.Duration
+}
+
+const (
+	defaultPINLength   = 6
+	minPINLength       = 4
+	maxPINLength       = 12
+	defaultMaxFailures = 3
+	defaultWindow      = 15 * time.Minute
+)
+
+func (cfg PINConfig) withDefaults() (PINConfig, error) {
+	if cfg.Length == 0 {
+		cfg.Length = defaultPINLength
+	}
+	if cfg.Length < minPINLength || cfg.Length > maxPINLength {
+		return cfg, fmt.Errorf("%w: length %d", ErrPINConfig, cfg.Length)
+	}
+	if cfg.MaxFailures == 0 {
+		cfg.MaxFailures = defaultMaxFailures
+	}
+	if cfg.Window == 0 {
+		cfg.Window = defaultWindow
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return cfg, nil
+}
+
+// PINChallenge is the challenge with the verifier's signed ephemeral key.
+type PINChallenge struct {
+	Challenge    Challenge    `json:"challenge"`
+	EphemeralKey chain.Base64 `json:"ephemeral_key"`
+	Signature    chain.Base64 `json:"signature"`
+}
+
+// PINResponse is the chain holder's answer to the PINChallenge.
+type PINResponse struct {
+	Challenge    PINChallenge `json:"challenge"`
+	EphemeralKey chain.Base64 `json:"ephemeral_key"`
+	Signature    chain.Base64 `json:"signature"`
+	MAC          chain.Base64 `json:"mac"`
+}
+
+func (c PINChallenge) signingInput() []byte {
+	d := append([]byte(pinVerifierDomain), c.Challenge.SigningInput()...)
+	return append(d, c.EphemeralKey...)
+}
+
+func transcript(c PINChallenge, responderEphemeral []byte) []byte {
+	d := append([]byte(pinDomain), c.signingInput()...)
+	return append(d, responderEphemeral...)
+}
+
+func pinMAC(dh []byte, pin string, nonce, transcript []byte) ([]byte, error) {
+	ikm := append(append([]byte{}, dh...), pin...)
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, nonce, []byte(pinDomain)), key); err != nil {
+		return nil, err
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write(transcript)
+	return m.Sum(nil), nil
+}
+
+// RespondPIN signs the PIN-bound challenge. The verifier is the identity of the
+// party the responder is talking to, and the pin is got from the other channel.
+func (r Responder) RespondPIN(
+	c PINChallenge,
+	verifier crypto.PubKey,
+	pin string,
+) (resp PINResponse, err error) {
+	defer err2.Handle(&err)
+
+	// checks audience, subject, and expiry
+	try.To1(r.Respond(c.Challenge, verifier))
+	if !crypto.VerifySign(verifier, c.signingInput(), c.Signature) {
+		return resp, fmt.Errorf("%w: verifier's ephemeral key", ErrBadSignature)
+	}
+
+	eph := crypto.RandSlice(curve25519.ScalarSize)
+	resp = PINResponse{
+		Challenge:    c,
+		EphemeralKey: try.To1(curve25519.X25519(eph, curve25519.Basepoint)),
+	}
+	dh, err := curve25519.X25519(eph, c.EphemeralKey)
+	if err != nil {
+		return resp, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	t := transcript(c, resp.EphemeralKey)
+	resp.Signature = r.key.Sign(t)
+	resp.MAC = try.To1(pinMAC(dh, pin, c.Challenge.Nonce, t))
+	return resp, nil
+}
+
+// PINVerifier issues PIN-bound challenges and verifies the responses. Like
+// Verifier it accepts every nonce only once, and in addition it rate limits the
+// failed attempts per chain holder key. PINVerifier is safe for concurrent use.
+type PINVerifier struct {
+	key crypto.KeyHandle
+	cfg PINConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	pending  map[string]pinState // key is nonce
+	used     map[string]time.Time
+	failures map[string][]time.Time // key is subject's fingerprint
+}
+
+type pinState struct {
+	challenge PINChallenge
+	ephemeral []byte
+	pin       string
+}
+
+// NewPINVerifier returns a PIN verifier which identity is the key.
+func NewPINVerifier(key crypto.KeyHandle, cfg PINConfig) (*PINVerifier, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &PINVerifier{
+		key:      key,
+		cfg:      cfg,
+		now:      time.Now,
+		pending:  make(map[string]pinState),
+		used:     make(map[string]time.Time),
+		failures: make(map[string][]time.Time),
+	}, nil
+}
+
+// New returns a new PIN-bound challenge for the holder of the chain c, and the
+// PIN which must be delivered to the holder over other, safe channel. It
+// returns ErrRateLimited if the holder has too many failed attempts.
+func (v *PINVerifier) New(c chain.Chain) (ch PINChallenge, pin string, err error) {
+	defer err2.Handle(&err)
+
+	leaf := try.To1(c.TryLeafPubKey())
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.prune()
+	if v.limited(leaf) {
+		return ch, "", ErrRateLimited
+	}
+
+	eph := crypto.RandSlice(curve25519.ScalarSize)
+	ch = PINChallenge{
+		Challenge: Challenge{
+			Nonce:    crypto.RandSlice(nonceLen),
+			Verifier: v.key.PublicKey(),
+			Subject:  leaf,
+			Expires:  v.now().Add(v.cfg.TTL).Truncate(time.Second),
+		},
+		EphemeralKey: try.To1(curve25519.X25519(eph, curve25519.Basepoint)),
+	}
+	ch.Signature = v.key.Sign(ch.signingInput())
+	pin = try.To1(newPIN(v.cfg.Length))
+	v.pending[string(ch.Challenge.Nonce)] = pinState{
+		challenge: ch,
+		ephemeral: eph,
+		pin:       pin,
+	}
+	return ch, pin, nil
+}
+
+// Verify checks that the response answers a challenge issued by v for the
+// chain c, that the responder knows the PIN, and that c is a valid chain now,
+// see Verifier.Verify. The nonce is consumed whatever the result is. Wrong
+// PINs from the subject's leaf key are counted for the rate limiting.
+func (v *PINVerifier) Verify(c chain.Chain, r PINResponse) (err error) {
+	if err := c.TryVerifyAt(v.now()); err != nil {
+		return fmt.Errorf("%w: %v", ErrChain, err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	defer v.prune()
+
+	nonce := string(r.Challenge.Challenge.Nonce)
+	if _, ok := v.used[nonce]; ok {
+		return ErrReplay
+	}
+	st, ok := v.pending[nonce]
+	if !ok {
+		return ErrUnknownNonce
+	}
+	delete(v.pending, nonce)
+	ch := st.challenge.Challenge
+	v.used[nonce] = ch.Expires
+
+	if !crypto.EqualBytes(ch.Subject, c.LeafPubKey()) {
+		return ErrSubject
+	}
+	if v.limited(ch.Subject) {
+		return ErrRateLimited
+	}
+	if v.now().After(ch.Expires) {
+		return ErrExpired
+	}
+	// we verify against our own copy of the challenge
+	t := transcript(st.challenge, r.EphemeralKey)
+	if !crypto.VerifySign(ch.Subject, t, r.Signature) {
+		return ErrBadSignature
+	}
+	dh, err := curve25519.X25519(st.ephemeral, r.EphemeralKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	mac, err := pinMAC(dh, st.pin, ch.Nonce, t)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, r.MAC) {
+		// only the subject can get here, i.e. others cannot lock it out
+		v.fail(ch.Subject)
+		return ErrPIN
+	}
+	return nil
+}
+
+// limited tells if the subject has too many failures in the window. It must be
+// called with the lock.
+func (v *PINVerifier) limited(subject crypto.PubKey) bool {
+	return len(v.failures[crypto.Fingerprint(subject)]) >= v.cfg.MaxFailures
+}
+
+func (v *PINVerifier) fail(subject crypto.PubKey) {
+	fp := crypto.Fingerprint(subject)
+	v.failures[fp] = append(v.failures[fp], v.now())
+}
+
+// prune removes the expired state. It must be called with the lock.
+func (v *PINVerifier) prune() {
+	now := v.now()
+	for nonce, st := range v.pending {
+		if now.After(st.challenge.Challenge.Expires.Add(v.cfg.TTL)) {
+			delete(v.pending, nonce)
+		}
+	}
+	for nonce, expires := range v.used {
+		if now.After(expires.Add(v.cfg.TTL)) {
+			delete(v.used, nonce)
+		}
+	}
+	for fp, times := range v.failures {
+		i := 0
+		for i < len(times) && now.Sub(times[i]) > v.cfg.Window {
+			i++
+		}
+		if i == len(times) {
+			delete(v.failures, fp)
+		} else {
+			v.failures[fp] = times[i:]
+		}
+	}
+}
+
+// newPIN returns uniformly random decimal PIN of the length.
+func newPIN(length int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
@@ -0,0 +1,147 @@
+package challenge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestPINChallenge(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{}))
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+
+	ch, pin := try.To2(v.New(aliceChain))
+	assert.Len(pin, defaultPINLength)
+
+	r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, pin))
+	assert.NoError(v.Verify(aliceChain, r))
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrReplay))
+}
+
+func TestPINWrong(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{Length: 8}))
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+
+	ch, pin := try.To2(v.New(aliceChain))
+	assert.Len(pin, 8)
+	r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, "00000000"+pin))
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrPIN))
+}
+
+func TestPINExpiredChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{}))
+	alice := try.To1(NewResponder(aliceKey, expiredChain))
+	ch, pin := try.To2(v.New(expiredChain))
+	r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, pin))
+	assert.That(errors.Is(v.Verify(expiredChain, r), ErrChain))
+}
+
+func TestPINRateLimit(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	now := time.Now()
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{MaxFailures: 2}))
+	v.now = func() time.Time { return now }
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+
+	for i := 0; i < 2; i++ {
+		ch, _ := try.To2(v.New(aliceChain))
+		r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, "wrong"))
+		assert.That(errors.Is(v.Verify(aliceChain, r), ErrPIN))
+	}
+	_, _, err := v.New(aliceChain)
+	assert.That(errors.Is(err, ErrRateLimited))
+
+	// other keys are not limited
+	_, _, err = v.New(bobChain)
+	assert.NoError(err)
+
+	// after the window alice can try again
+	v.now = func() time.Time { return now.Add(defaultWindow + time.Second) }
+	ch, pin := try.To2(v.New(aliceChain))
+	r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, pin))
+	assert.NoError(v.Verify(aliceChain, r))
+}
+
+func TestPINRateLimitUnauthenticated(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// anyone with alice's public chain sends garbage to lock her out
+	now := time.Now()
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{MaxFailures: 2}))
+	v.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		ch, _ := try.To2(v.New(aliceChain))
+		r := PINResponse{
+			Challenge:    ch,
+			EphemeralKey: crypto.RandSlice(32),
+			Signature:    crypto.RandSlice(64),
+			MAC:          crypto.RandSlice(32),
+		}
+		assert.That(errors.Is(v.Verify(aliceChain, r), ErrBadSignature))
+	}
+	ch, _ := try.To2(v.New(aliceChain))
+	v.now = func() time.Time { return now.Add(time.Hour) }
+	err := v.Verify(aliceChain, PINResponse{Challenge: ch})
+	assert.That(errors.Is(err, ErrExpired))
+	v.now = func() time.Time { return now }
+
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+	ch, pin := try.To2(v.New(aliceChain))
+	r := try.To1(alice.RespondPIN(ch, verifierKey.PubKey, pin))
+	assert.NoError(v.Verify(aliceChain, r))
+}
+
+func TestPINVerifierImpersonation(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// mallory relays a challenge but replaces the ephemeral key to be able
+	// to brute force the PIN offline
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{}))
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+	ch, pin := try.To2(v.New(aliceChain))
+	ch.EphemeralKey = crypto.RandSlice(32)
+	_, err := alice.RespondPIN(ch, verifierKey.PubKey, pin)
+	assert.That(errors.Is(err, ErrBadSignature))
+
+	// mallory's own challenge isn't accepted when alice expects the verifier
+	mallory := try.To1(NewPINVerifier(crypto.NewKey(), PINConfig{}))
+	ch, pin = try.To2(mallory.New(aliceChain))
+	_, err = alice.RespondPIN(ch, verifierKey.PubKey, pin)
+	assert.That(errors.Is(err, ErrAudience))
+}
+
+func TestPINResponseOtherKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := try.To1(NewPINVerifier(verifierKey, PINConfig{}))
+	ch, pin := try.To2(v.New(aliceChain))
+
+	// bob knows the PIN but doesn't have alice's key
+	bob := Responder{key: bobKey, chain: aliceChain, now: time.Now}
+	ch.Challenge.Subject = bobKey.PubKey
+	_, err := bob.RespondPIN(ch, verifierKey.PubKey, pin)
+	assert.That(errors.Is(err, ErrBadSignature), "subject is signed too")
+}
+
+func TestPINConfig(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, l := range []int{-1, 3, 13} {
+		_, err := NewPINVerifier(verifierKey, PINConfig{Length: l})
+		assert.That(errors.Is(err, ErrPINConfig))
+	}
+	for l := minPINLength; l <= maxPINLength; l++ {
+		assert.Len(try.To1(newPIN(l)), l)
+	}
+}
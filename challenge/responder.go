@@ -0,0 +1,57 @@
+package challenge
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+// Responder signs challenges for its own chain. It signs only well-formed,
+// unexpired challenges for its own leaf key and the expected verifier, i.e. it
+// cannot be used as a signing oracle.
+type Responder struct {
+	key   crypto.KeyHandle
+	chain chain.Chain
+	now   func() time.Time
+}
+
+// NewResponder returns a responder of the chain. The key must be the chain's
+// leaf key.
+func NewResponder(key crypto.KeyHandle, c chain.Chain) (r Responder, err error) {
+	leaf, err := c.TryLeafPubKey()
+	if err != nil {
+		return r, err
+	}
+	if !crypto.EqualBytes(leaf, key.PublicKey()) {
+		return r, chain.ErrNotLeaf
+	}
+	return Responder{key: key, chain: c, now: time.Now}, nil
+}
+
+// Chain returns the chain that the responder presents to the verifiers.
+func (r Responder) Chain() chain.Chain {
+	return r.chain
+}
+
+// Respond signs the challenge. The verifier is the identity of the party the
+// responder is talking to. It must match to the challenge's audience.
+func (r Responder) Respond(c Challenge, verifier crypto.PubKey) (Response, error) {
+	if err := c.wellFormed(); err != nil {
+		return Response{}, err
+	}
+	if !crypto.EqualBytes(c.Verifier, verifier) {
+		return Response{}, ErrAudience
+	}
+	if !crypto.EqualBytes(c.Subject, r.key.PublicKey()) {
+		return Response{}, ErrSubject
+	}
+	if r.now().After(c.Expires) {
+		return Response{}, fmt.Errorf("%w: at %v", ErrExpired, c.Expires)
+	}
+	return Response{
+		Challenge: c,
+		Signature: r.key.Sign(c.SigningInput()),
+	}, nil
+}
@@ -0,0 +1,140 @@
+package challenge
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+// DefaultTTL is the default lifetime of the challenges.
+const DefaultTTL = 2 * time.Minute
+
+// Verifier issues challenges and verifies the responses. Every issued nonce
+// can be used only once, i.e. replayed responses are rejected. Verifier is
+// safe for concurrent use.
+type Verifier struct {
+	id  crypto.PubKey
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]Challenge // issued but not yet answered, key is nonce
+	used    map[string]time.Time // answered nonces and their expiry times
+}
+
+// NewVerifier returns a verifier which identity, i.e. the challenge audience,
+// is the id. If ttl is zero DefaultTTL is used.
+func NewVerifier(id crypto.PubKey, ttl time.Duration) *Verifier {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Verifier{
+		id:      id,
+		ttl:     ttl,
+		now:     time.Now,
+		pending: make(map[string]Challenge),
+		used:    make(map[string]time.Time),
+	}
+}
+
+// New returns a new challenge for the holder of the chain c.
+func (v *Verifier) New(c chain.Chain) (Challenge, error) {
+	leaf, err := c.TryLeafPubKey()
+	if err != nil {
+		return Challenge{}, err
+	}
+	ch := Challenge{
+		Nonce:    crypto.RandSlice(nonceLen),
+		Verifier: v.id,
+		Subject:  leaf,
+		Expires:  v.now().Add(v.ttl).Truncate(time.Second),
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.prune()
+	v.pending[string(ch.Nonce)] = ch
+	return ch, nil
+}
+
+// Restore adds the challenge, which v has issued earlier, back to the pending
+// challenges, e.g. when the issued challenges are stored to files between the
+// processes. The caller must restore only its own copies of the challenges
+// and each of them only once. It returns ErrMalformed or ErrAudience if the
+// challenge isn't one of v's.
+func (v *Verifier) Restore(ch Challenge) error {
+	if err := ch.wellFormed(); err != nil {
+		return err
+	}
+	if !crypto.EqualBytes(ch.Verifier, v.id) {
+		return ErrAudience
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.prune()
+	if _, ok := v.used[string(ch.Nonce)]; ok {
+		return ErrReplay
+	}
+	v.pending[string(ch.Nonce)] = ch
+	return nil
+}
+
+// Verify checks that the response answers a challenge issued by v for the
+// chain c, and that c is a valid chain now, i.e. its validity windows are
+// checked as well. The nonce is consumed, i.e. the same response is accepted
+// only once.
+func (v *Verifier) Verify(c chain.Chain, r Response) error {
+	if err := c.TryVerifyAt(v.now()); err != nil {
+		return fmt.Errorf("%w: %v", ErrChain, err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	defer v.prune()
+
+	nonce := string(r.Challenge.Nonce)
+	if _, ok := v.used[nonce]; ok {
+		return ErrReplay
+	}
+	ch, ok := v.pending[nonce]
+	if !ok {
+		return ErrUnknownNonce
+	}
+	// the challenge is consumed whatever the result is, no second tries
+	delete(v.pending, nonce)
+	v.used[nonce] = ch.Expires
+
+	if !crypto.EqualBytes(ch.Subject, c.LeafPubKey()) {
+		return ErrSubject
+	}
+	if v.now().After(ch.Expires) {
+		return ErrExpired
+	}
+	// we verify our own copy of the challenge, not what the response claims
+	if !crypto.VerifySign(ch.Subject, ch.SigningInput(), r.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// prune removes the expired challenges and used nonces. It must be called
+// with the lock.
+func (v *Verifier) prune() {
+	// after the expiry the nonces cannot be accepted anyway, but we keep
+	// them one TTL more to give better errors
+	now := v.now()
+	for nonce, ch := range v.pending {
+		if now.After(ch.Expires.Add(v.ttl)) {
+			delete(v.pending, nonce)
+		}
+	}
+	for nonce, expires := range v.used {
+		if now.After(expires.Add(v.ttl)) {
+			delete(v.used, nonce)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package challenge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestVerifierReplay(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, time.Minute)
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+
+	r := try.To1(alice.Respond(try.To1(v.New(aliceChain)), verifierKey.PubKey))
+	assert.NoError(v.Verify(aliceChain, r))
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrReplay))
+
+	// other verifier hasn't issued the nonce
+	v2 := NewVerifier(verifierKey.PubKey, time.Minute)
+	assert.That(errors.Is(v2.Verify(aliceChain, r), ErrUnknownNonce))
+}
+
+func TestVerifierRestore(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, time.Minute)
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+	ch := try.To1(v.New(aliceChain))
+	r := try.To1(alice.Respond(ch, verifierKey.PubKey))
+
+	// e.g. the verifier process restarts between the steps
+	v = NewVerifier(verifierKey.PubKey, time.Minute)
+	try.To(v.Restore(ch))
+	assert.NoError(v.Verify(aliceChain, r))
+	assert.That(errors.Is(v.Restore(ch), ErrReplay))
+
+	other := NewVerifier(crypto.NewKey().PubKey, time.Minute)
+	assert.That(errors.Is(other.Restore(ch), ErrAudience))
+	ch.Nonce = ch.Nonce[1:]
+	assert.That(errors.Is(v.Restore(ch), ErrMalformed))
+}
+
+func TestVerifierRejects(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, time.Minute)
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+	bob := try.To1(NewResponder(bobKey, bobChain))
+
+	// alice answers but presents bob's chain
+	r := try.To1(alice.Respond(try.To1(v.New(aliceChain)), verifierKey.PubKey))
+	assert.That(errors.Is(v.Verify(bobChain, r), ErrSubject))
+
+	// response signature by someone else
+	ch := try.To1(v.New(aliceChain))
+	r = Response{Challenge: ch, Signature: bobKey.Sign(ch.SigningInput())}
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrBadSignature))
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrReplay),
+		"failed attempts consume the nonce")
+
+	// tampered response challenge doesn't help, our own copy is verified
+	ch = try.To1(v.New(bobChain))
+	r = try.To1(bob.Respond(ch, verifierKey.PubKey))
+	r.Challenge.Expires = r.Challenge.Expires.Add(time.Hour)
+	assert.NoError(v.Verify(bobChain, r))
+
+	// broken chain
+	broken := aliceChain.Clone()
+	broken.Blocks[1].InvitersSignature[0] ^= 0x01
+	r = try.To1(alice.Respond(try.To1(v.New(aliceChain)), verifierKey.PubKey))
+	assert.That(errors.Is(v.Verify(broken, r), ErrChain))
+}
+
+func TestVerifierExpiry(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	now := time.Now()
+	v := NewVerifier(crypto.NewKey().PubKey, time.Minute)
+	v.now = func() time.Time { return now }
+	alice := try.To1(NewResponder(aliceKey, aliceChain))
+
+	ch := try.To1(v.New(aliceChain))
+	r := try.To1(alice.Respond(ch, v.id))
+	v.now = func() time.Time { return now.Add(90 * time.Second) }
+	assert.That(errors.Is(v.Verify(aliceChain, r), ErrExpired))
+
+	// old nonces are forgotten after expiry and one TTL
+	ch = try.To1(v.New(aliceChain))
+	assert.MLen(v.used, 1)
+	assert.MLen(v.pending, 1)
+	v.now = func() time.Time { return now.Add(time.Hour) }
+	v.prune()
+	assert.MLen(v.used, 0)
+	assert.MLen(v.pending, 0)
+}
+
+func TestVerifierExpiredChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	v := NewVerifier(verifierKey.PubKey, time.Minute)
+	alice := try.To1(NewResponder(aliceKey, expiredChain))
+	r := try.To1(alice.Respond(try.To1(v.New(expiredChain)), verifierKey.PubKey))
+	assert.That(expiredChain.Verify(), "only the validity window is wrong")
+	assert.That(errors.Is(v.Verify(expiredChain, r), ErrChain))
+}
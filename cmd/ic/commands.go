@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/keystore"
+)
+
+func keygen(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("keygen")
+	out := fs.String("out", "", "key file to create")
+	algName := fs.String("alg", crypto.Ed25519.String(),
+		"key algorithm: ed25519 or p256")
+	try.To(parse(fs, args, 0))
+	if *out == "" {
+		return errUsage
+	}
+
+	key := try.To1(crypto.GenerateKey(try.To1(crypto.ParseAlgorithm(*algName))))
+	try.To(keystore.Save(*out, key, try.To1(a.passphrase(fs))))
+	fmt.Fprintln(a.stdout, encodeKey(key.PubKey))
+	return nil
+}
+
+func root(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("root")
+	keyFile := fs.String("key", "", "key file of the root")
+	out := fs.String("out", "", "chain file to create")
+	asJSON := fs.Bool("json", false, "write the chain as JSON")
+	try.To(parse(fs, args, 0))
+	if *keyFile == "" || *out == "" {
+		return errUsage
+	}
+
+	pubKey := try.To1(keystore.PubKey(try.To1(os.ReadFile(*keyFile))))
+	return writeChain(*out, chain.NewRootChain(pubKey), *asJSON)
+}
+
+func invite(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("invite")
+	keyFile := fs.String("key", "", "key file of the inviter")
+	in := fs.String("in", "", "chain file of the inviter")
+	pubKey := fs.String("pubkey", "", "invitee's public key, base64url")
+	position := fs.Int("position", int(chain.MemberPosition),
+		"invitee's position")
+	out := fs.String("out", "", "chain file to create for the invitee")
+	asJSON := fs.Bool("json", false, "write the chain as JSON")
+	try.To(parse(fs, args, 0))
+	if *keyFile == "" || *in == "" || *pubKey == "" || *out == "" {
+		return errUsage
+	}
+
+	c := try.To1(readChain(*in))
+	try.To(c.TryVerify())
+	invitee := try.To1(decodeKey(*pubKey))
+	key := try.To1(keystore.Open(*keyFile, try.To1(a.passphrase(fs))))
+	nc := try.To1(c.TryInvite(key, invitee, chain.Position(*position)))
+	return writeChain(*out, nc, *asJSON)
+}
+
+func verify(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("verify")
+	at := fs.String("at", "",
+		"check validity windows at the time, RFC 3339 or 'now'")
+	try.To(parse(fs, args, 1))
+
+	c := try.To1(readChain(fs.Arg(0)))
+	r := c.VerifyReport()
+	if *at != "" {
+		r = c.VerifyReportAt(try.To1(parseTime(*at)))
+	}
+	if !r.OK() {
+		fmt.Fprintln(a.stdout, "invalid:", r.Err())
+		return errInvalid
+	}
+	fmt.Fprintln(a.stdout, "ok")
+	return nil
+}
+
+func inspect(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("inspect")
+	asJSON := fs.Bool("json", false, "print the chain as JSON")
+	try.To(parse(fs, args, 1))
+
+	c := try.To1(readChain(fs.Arg(0)))
+	if *asJSON {
+		d := try.To1(json.MarshalIndent(c, "", "  "))
+		fmt.Fprintln(a.stdout, string(d))
+		return nil
+	}
+	fmt.Fprintln(a.stdout, c)
+	for i, b := range c.Blocks {
+		fmt.Fprintf(a.stdout, "%d: key=%s fingerprint=%s position=%v kind=%v",
+			i, encodeKey(b.InviteePubKey),
+			crypto.Fingerprint(b.InviteePubKey), b.Position, b.Kind)
+		if !b.Validity.Unlimited() {
+			fmt.Fprintf(a.stdout, " not_before=%s not_after=%s",
+				formatTime(b.NotBefore), formatTime(b.NotAfter))
+		}
+		fmt.Fprintln(a.stdout)
+	}
+	if err := c.TryVerify(); err != nil {
+		fmt.Fprintln(a.stdout, "invalid:", err)
+	}
+	return nil
+}
+
+func hops(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("hops")
+	try.To(parse(fs, args, 2))
+
+	c1, c2 := try.To1(readChain(fs.Arg(0))), try.To1(readChain(fs.Arg(1)))
+	h, common := chain.Hops(c1, c2)
+	if h == chain.NotConnected {
+		fmt.Fprintln(a.stdout, "not connected")
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "hops=%d common_inviter=%d\n", h, common)
+	return nil
+}
+
+func common(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("common")
+	try.To(parse(fs, args, 2))
+
+	c1, c2 := try.To1(readChain(fs.Arg(0))), try.To1(readChain(fs.Arg(1)))
+	level := chain.CommonInviter(c1, c2)
+	if level == chain.NotConnected {
+		fmt.Fprintln(a.stdout, "not connected")
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "common_inviter=%d position=%v\n",
+		level, c1.PositionAt(level))
+	return nil
+}
+
+// challengeCmd runs the challenge-response protocol of package challenge over
+// files. The verifier issues a challenge for the holder's chain and keeps its
+// own copy of the challenge file. The holder responds with its leaf key, and
+// the verifier checks the response against its own copy, which is removed,
+// i.e. every challenge can be verified only once.
+func challengeCmd(a *app, args []string) (err error) {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "issue":
+		return challengeIssue(a, args[1:])
+	case "respond":
+		return challengeRespond(a, args[1:])
+	case "verify":
+		return challengeVerify(a, args[1:])
+	}
+	return errUsage
+}
+
+func challengeIssue(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("challenge issue")
+	keyFile := fs.String("key", "", "key file of the verifier")
+	ttl := fs.Duration("ttl", challenge.DefaultTTL, "lifetime of the challenge")
+	out := fs.String("out", "", "challenge file to create")
+	try.To(parse(fs, args, 1))
+	if *keyFile == "" || *out == "" {
+		return errUsage
+	}
+
+	c := try.To1(readChain(fs.Arg(0)))
+	try.To(c.TryVerify())
+	id := try.To1(keystore.PubKey(try.To1(os.ReadFile(*keyFile))))
+	ch := try.To1(challenge.NewVerifier(id, *ttl).New(c))
+	return writeJSON(*out, ch)
+}
+
+func challengeRespond(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("challenge respond")
+	keyFile := fs.String("key", "", "key file of the chain holder")
+	verifier := fs.String("verifier", "", "verifier's public key, base64url")
+	in := fs.String("in", "", "challenge file from the verifier")
+	out := fs.String("out", "", "response file to create")
+	try.To(parse(fs, args, 1))
+	if *keyFile == "" || *verifier == "" || *in == "" || *out == "" {
+		return errUsage
+	}
+
+	c := try.To1(readChain(fs.Arg(0)))
+	id := try.To1(decodeKey(*verifier))
+	var ch challenge.Challenge
+	try.To(readJSON(*in, &ch))
+	key := try.To1(keystore.Open(*keyFile, try.To1(a.passphrase(fs))))
+	r := try.To1(try.To1(challenge.NewResponder(key, c)).Respond(ch, id))
+	return writeJSON(*out, r)
+}
+
+func challengeVerify(a *app, args []string) (err error) {
+	defer err2.Handle(&err)
+
+	fs := a.flagSet("challenge verify")
+	chFile := fs.String("challenge", "", "verifier's own copy of the challenge")
+	in := fs.String("in", "", "response file from the chain holder")
+	try.To(parse(fs, args, 1))
+	if *chFile == "" || *in == "" {
+		return errUsage
+	}
+
+	c := try.To1(readChain(fs.Arg(0)))
+	var ch challenge.Challenge
+	try.To(readJSON(*chFile, &ch))
+	var r challenge.Response
+	try.To(readJSON(*in, &r))
+	v := challenge.NewVerifier(ch.Verifier, 0)
+	try.To(v.Restore(ch))
+	// the nonce is consumed whatever the result is
+	try.To(os.Remove(*chFile))
+	if err := v.Verify(c, r); err != nil {
+		fmt.Fprintln(a.stdout, "failed:", err)
+		return errInvalid
+	}
+	fmt.Fprintln(a.stdout, "ok")
+	return nil
+}
+
+// flagSet returns a flag set which writes its output to the app's stderr. It
+// has the -passphrase-file flag, which is used only by the commands that need
+// the passphrase.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.String("passphrase-file", "", "file of the key file passphrase")
+	return fs
+}
+
+// parse parses the args and checks that there are nArgs positional arguments.
+func parse(fs *flag.FlagSet, args []string, nArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != nArgs {
+		return errUsage
+	}
+	return nil
+}
+
+// passphrase reads the passphrase from the -passphrase-file or from the
+// IC_PASSPHRASE environment variable.
+func (a *app) passphrase(fs *flag.FlagSet) (p []byte, err error) {
+	defer err2.Handle(&err)
+
+	if f := fs.Lookup("passphrase-file").Value.String(); f != "" {
+		d := try.To1(os.ReadFile(f))
+		return bytes.TrimRight(d, "\r\n"), nil
+	}
+	if p := a.getenv("IC_PASSPHRASE"); p != "" {
+		return []byte(p), nil
+	}
+	return nil, fmt.Errorf("no passphrase: use -passphrase-file or IC_PASSPHRASE")
+}
+
+// readChain reads the chain file. JSON files are detected by their content.
+func readChain(path string) (c chain.Chain, err error) {
+	defer err2.Handle(&err)
+
+	d := try.To1(os.ReadFile(path))
+	if t := bytes.TrimSpace(d); len(t) > 0 && t[0] == '{' {
+		try.To(json.Unmarshal(t, &c))
+		if c.Len() == 0 {
+			return c, chain.ErrEmptyChain
+		}
+		return c, nil
+	}
+	return chain.ParseChain(d)
+}
+
+// writeChain writes the chain file. Existing files aren't overwritten.
+func writeChain(path string, c chain.Chain, asJSON bool) (err error) {
+	defer err2.Handle(&err)
+
+	if asJSON {
+		return writeJSON(path, c)
+	}
+	return writeFile(path, try.To1(c.TryBytes()))
+}
+
+// readJSON reads the JSON file to the v.
+func readJSON(path string, v any) (err error) {
+	defer err2.Handle(&err)
+
+	return json.Unmarshal(try.To1(os.ReadFile(path)), v)
+}
+
+// writeJSON writes the v as an indented JSON file, see writeFile.
+func writeJSON(path string, v any) (err error) {
+	defer err2.Handle(&err)
+
+	d := append(try.To1(json.MarshalIndent(v, "", "  ")), '\n')
+	return writeFile(path, d)
+}
+
+// writeFile writes the new file. Existing files aren't overwritten, and the
+// file is removed if the write fails, i.e. the command can be retried.
+func writeFile(path string, d []byte) (err error) {
+	defer err2.Handle(&err)
+
+	f := try.To1(os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644))
+	defer err2.Handle(&err, func(err error) error {
+		_ = os.Remove(path)
+		return err
+	})
+	_, err = f.Write(d)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func encodeKey(pubKey crypto.PubKey) string {
+	return base64.RawURLEncoding.EncodeToString(pubKey)
+}
+
+func decodeKey(s string) (crypto.PubKey, error) {
+	pubKey, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	if _, _, err := crypto.ParsePubKey(pubKey); err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	return pubKey, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "now" {
+		return time.Now(), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,107 @@
+// Command ic manages invitation chains from the command line. The chains are
+// read and written as files in the canonical binary encoding, or in JSON with
+// the -json flag. The keys are encrypted key files of package keystore, and
+// their passphrase is read from the file given with -passphrase-file or from
+// the IC_PASSPHRASE environment variable.
+//
+// Usage:
+//
+//	ic keygen [-alg ed25519|p256] -out key.json
+//	ic root -key key.json -out root.chain
+//	ic invite -key key.json -in my.chain -pubkey KEY -position 1 -out new.chain
+//	ic verify [-at TIME] file.chain
+//	ic inspect [-json] file.chain
+//	ic hops a.chain b.chain
+//	ic common a.chain b.chain
+//	ic challenge issue -key verifier.json [-ttl 2m] -out ch.json holder.chain
+//	ic challenge respond -key key.json -verifier KEY -in ch.json -out r.json \
+//		holder.chain
+//	ic challenge verify -challenge ch.json -in r.json holder.chain
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of ic. The run function gets the arguments after the
+// subcommand name.
+type command struct {
+	usage string
+	run   func(a *app, args []string) error
+}
+
+// challengeUsage lists the steps of the challenge command.
+const challengeUsage = `challenge issue -key FILE [-ttl DURATION] -out FILE FILE
+  ic challenge respond -key FILE -verifier KEY -in FILE -out FILE FILE
+  ic challenge verify -challenge FILE -in FILE FILE`
+
+var commands = map[string]command{
+	"keygen":    {"keygen [-alg ed25519|p256] -out FILE", keygen},
+	"root":      {"root -key FILE -out FILE", root},
+	"invite":    {"invite -key FILE -in FILE -pubkey KEY -position N -out FILE", invite},
+	"verify":    {"verify [-at TIME] FILE", verify},
+	"inspect":   {"inspect [-json] FILE", inspect},
+	"hops":      {"hops FILE FILE", hops},
+	"common":    {"common FILE FILE", common},
+	"challenge": {challengeUsage, challengeCmd},
+}
+
+var (
+	// errUsage tells that the command line is wrong. The usage is printed.
+	errUsage = errors.New("usage")
+
+	// errInvalid tells that the chain or the challenge response didn't
+	// verify. It's the exit status 1 without other output than the command's
+	// own.
+	errInvalid = errors.New("invalid chain")
+)
+
+// app is the environment of the commands. Tests replace its fields.
+type app struct {
+	stdout, stderr io.Writer
+	getenv         func(string) string
+}
+
+func main() {
+	a := &app{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	if err := a.run(os.Args[1:]); err != nil {
+		if !errors.Is(err, errInvalid) && !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "ic:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(args []string) error {
+	if len(args) == 0 {
+		a.usage()
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.stderr, "ic: unknown command %q\n", args[0])
+		a.usage()
+		return errUsage
+	}
+	err := cmd.run(a, args[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(a.stderr, "usage: ic", cmd.usage)
+	}
+	return err
+}
+
+func (a *app) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(a.stderr, "usage:")
+	for _, name := range names {
+		fmt.Fprintln(a.stderr, "  ic", commands[name].usage)
+	}
+}
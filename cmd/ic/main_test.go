@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+)
+
+// ic runs the command in the dir and returns its stdout.
+func ic(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string {
+			if k == "IC_PASSPHRASE" {
+				return "secret"
+			}
+			return ""
+		},
+	}
+	for i, arg := range args {
+		if strings.HasSuffix(arg, ".chain") || strings.HasSuffix(arg, ".json") {
+			args[i] = filepath.Join(dir, arg)
+		}
+	}
+	err := a.run(args)
+	return strings.TrimSpace(stdout.String()), err
+}
+
+func TestCommands(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	try.To1(ic(dir, "keygen", "-out", "root.json"))
+	alicePub := try.To1(ic(dir, "keygen", "-out", "alice.json"))
+	bobPub := try.To1(ic(dir, "keygen", "-out", "bob.json"))
+
+	try.To1(ic(dir, "root", "-key", "root.json", "-out", "root.chain"))
+	try.To1(ic(dir, "invite", "-key", "root.json", "-in", "root.chain",
+		"-pubkey", alicePub, "-position", "1", "-out", "alice.chain"))
+	try.To1(ic(dir, "invite", "-key", "root.json", "-in", "root.chain",
+		"-pubkey", bobPub, "-position", "1", "-json", "-out", "bob.chain"))
+
+	assert.Equal(try.To1(ic(dir, "verify", "alice.chain")), "ok")
+	assert.Equal(try.To1(ic(dir, "verify", "-at", "now", "bob.chain")), "ok")
+	assert.Equal(try.To1(ic(dir, "hops", "alice.chain", "bob.chain")),
+		"hops=2 common_inviter=0")
+	assert.Equal(try.To1(ic(dir, "common", "alice.chain", "bob.chain")),
+		"common_inviter=0 position=root")
+	// bob challenges alice
+	try.To1(ic(dir, "challenge", "issue", "-key", "bob.json",
+		"-out", "ch.json", "alice.chain"))
+	try.To1(ic(dir, "challenge", "respond", "-key", "alice.json",
+		"-verifier", bobPub, "-in", "ch.json", "-out", "r.json", "alice.chain"))
+	assert.Equal(try.To1(ic(dir, "challenge", "verify", "-challenge", "ch.json",
+		"-in", "r.json", "alice.chain")), "ok")
+
+	out := try.To1(ic(dir, "inspect", "alice.chain"))
+	assert.That(strings.Contains(out, "len=2"))
+	assert.That(strings.Contains(out, alicePub))
+	out = try.To1(ic(dir, "inspect", "-json", "bob.chain"))
+	assert.That(strings.Contains(out, `"blocks"`))
+}
+
+func TestCommandFailures(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	rootPub := try.To1(ic(dir, "keygen", "-out", "root.json"))
+	alicePub := try.To1(ic(dir, "keygen", "-out", "alice.json"))
+	try.To1(ic(dir, "root", "-key", "root.json", "-out", "root.chain"))
+	try.To1(ic(dir, "invite", "-key", "root.json", "-in", "root.chain",
+		"-pubkey", alicePub, "-out", "alice.chain"))
+
+	// only the leaf can invite
+	_, err := ic(dir, "invite", "-key", "root.json", "-in", "alice.chain",
+		"-pubkey", alicePub, "-out", "x.chain")
+	assert.Error(err)
+
+	// the key doesn't hold the chain
+	try.To1(ic(dir, "challenge", "issue", "-key", "root.json",
+		"-out", "ch.json", "alice.chain"))
+	_, err = ic(dir, "challenge", "respond", "-key", "root.json",
+		"-verifier", rootPub, "-in", "ch.json", "-out", "r.json", "alice.chain")
+	assert.That(errors.Is(err, chain.ErrNotLeaf))
+	_, err = ic(dir, "challenge", "respond", "-key", "alice.json",
+		"-verifier", alicePub, "-in", "ch.json", "-out", "r.json", "alice.chain")
+	assert.That(errors.Is(err, challenge.ErrAudience))
+
+	// the response is accepted only once
+	try.To1(ic(dir, "challenge", "respond", "-key", "alice.json",
+		"-verifier", rootPub, "-in", "ch.json", "-out", "r.json", "alice.chain"))
+	try.To1(ic(dir, "challenge", "verify", "-challenge", "ch.json",
+		"-in", "r.json", "alice.chain"))
+	_, err = ic(dir, "challenge", "verify", "-challenge", "ch.json",
+		"-in", "r.json", "alice.chain")
+	assert.That(errors.Is(err, os.ErrNotExist))
+
+	// the response must answer the verifier's own copy of the challenge
+	try.To1(ic(dir, "challenge", "issue", "-key", "root.json",
+		"-out", "ch2.json", "alice.chain"))
+	out, err := ic(dir, "challenge", "verify", "-challenge", "ch2.json",
+		"-in", "r.json", "alice.chain")
+	assert.That(errors.Is(err, errInvalid))
+	assert.That(strings.HasPrefix(out, "failed:"))
+	_, err = ic(dir, "challenge", "nope")
+	assert.That(errors.Is(err, errUsage))
+
+	// tampered chain
+	path := filepath.Join(dir, "alice.chain")
+	d := try.To1(os.ReadFile(path))
+	d[len(d)-1] ^= 0x01
+	try.To(os.WriteFile(path, d, 0o644))
+	out, err = ic(dir, "verify", "alice.chain")
+	assert.That(errors.Is(err, errInvalid))
+	assert.That(strings.HasPrefix(out, "invalid:"))
+
+	_, err = ic(dir, "hops", "alice.chain")
+	assert.That(errors.Is(err, errUsage))
+	_, err = ic(dir, "nope")
+	assert.That(errors.Is(err, errUsage))
+	_, err = ic(dir, "root", "-key", "root.json", "-out", "root.chain")
+	assert.Error(err, "existing files aren't overwritten")
+	_, err = ic(dir, "keygen", "-alg", "rsa", "-out", "rsa.json")
+	assert.Error(err)
+	_, err = ic(dir, "invite", "-key", "root.json", "-in", "root.chain",
+		"-pubkey", "AAAA", "-out", "x.chain")
+	assert.Error(err, "malformed public key")
+}
+
+func TestCommandsP256(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	try.To1(ic(dir, "keygen", "-alg", "p256", "-out", "root.json"))
+	alicePub := try.To1(ic(dir, "keygen", "-alg", "p256", "-out", "alice.json"))
+	bobPub := try.To1(ic(dir, "keygen", "-out", "bob.json"))
+
+	try.To1(ic(dir, "root", "-key", "root.json", "-out", "root.chain"))
+	try.To1(ic(dir, "invite", "-key", "root.json", "-in", "root.chain",
+		"-pubkey", alicePub, "-position", "1", "-out", "alice.chain"))
+	try.To1(ic(dir, "invite", "-key", "alice.json", "-in", "alice.chain",
+		"-pubkey", bobPub, "-position", "1", "-out", "bob.chain"))
+
+	assert.Equal(try.To1(ic(dir, "verify", "bob.chain")), "ok")
+	try.To1(ic(dir, "challenge", "issue", "-key", "bob.json",
+		"-out", "ch.json", "alice.chain"))
+	try.To1(ic(dir, "challenge", "respond", "-key", "alice.json",
+		"-verifier", bobPub, "-in", "ch.json", "-out", "r.json", "alice.chain"))
+	assert.Equal(try.To1(ic(dir, "challenge", "verify", "-challenge", "ch.json",
+		"-in", "r.json", "alice.chain")), "ok")
+}
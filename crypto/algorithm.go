@@ -0,0 +1,280 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Algorithm identifies the signature algorithm of a key. The algorithms are
+// implemented by Schemes, which are registered with Register.
+//
+// The public keys are algorithm-tagged: PubKey is the tag byte followed by the
+// raw public key of the algorithm. Because the chains were ed25519 only
+// before the tags, the ed25519 keys are the exception: they are the raw 32
+// byte keys without the tag.
+type Algorithm uint8
+
+const (
+	// Ed25519 is the default algorithm. The secrets are the 32 byte seeds.
+	Ed25519 Algorithm = iota
+
+	// P256 is ECDSA with the NIST P-256 curve and SHA-256. The public keys
+	// are SEC 1 compressed points, the secrets are the 32 byte scalars and
+	// the signatures are ASN.1 DER encoded. It's for the platform keystores
+	// which don't support ed25519.
+	//
+	// ECDSA signatures are malleable, i.e. (r, n-s) is valid when (r, s) is.
+	// Because the block hashes include the signatures, only the canonical
+	// signatures are accepted: DER encoded and s at most n/2, i.e. low-S.
+	P256
+)
+
+var ErrAlgorithm = errors.New("unsupported key algorithm")
+
+// Scheme implements a signature Algorithm. The keys are given and returned
+// raw, i.e. without the algorithm tag.
+type Scheme interface {
+	// PubKeySize is the size of the raw public key.
+	PubKeySize() int
+
+	// SecretSize is the size of the secret.
+	SecretSize() int
+
+	// NewKey returns the private and the public key of the secret.
+	NewKey(secret []byte) (priv, pub []byte, err error)
+
+	// Secret returns the secret of the private key, see NewKey.
+	Secret(priv []byte) []byte
+
+	Sign(priv, msg []byte) (Signature, error)
+
+	// Verify never panics, i.e. malformed keys and signatures fail.
+	Verify(pub, msg []byte, sig Signature) bool
+}
+
+type algorithm struct {
+	name string
+	Scheme
+}
+
+var algorithms = map[Algorithm]algorithm{
+	Ed25519: {"ed25519", ed25519Scheme{}},
+	P256:    {"p256", p256Scheme{}},
+}
+
+// Register adds the scheme of the algorithm with its name. It panics if the
+// algorithm or the name is already registered, or the tagged public keys
+// would be mistaken for ed25519 keys. Register isn't safe for concurrent use,
+// call it from init functions.
+func Register(alg Algorithm, name string, s Scheme) {
+	if 1+s.PubKeySize() == ed25519.PublicKeySize {
+		panic(fmt.Sprintf("crypto: algorithm %q key size", name))
+	}
+	if _, ok := algorithms[alg]; ok {
+		panic(fmt.Sprintf("crypto: algorithm %d registered twice", alg))
+	}
+	if _, err := ParseAlgorithm(name); err == nil {
+		panic(fmt.Sprintf("crypto: algorithm %q registered twice", name))
+	}
+	algorithms[alg] = algorithm{name: name, Scheme: s}
+}
+
+// Lookup returns the scheme of the algorithm.
+func Lookup(alg Algorithm) (Scheme, bool) {
+	a, ok := algorithms[alg]
+	return a.Scheme, ok
+}
+
+// ParseAlgorithm returns the algorithm of the registered name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for alg, a := range algorithms {
+		if a.name == name {
+			return alg, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrAlgorithm, name)
+}
+
+func (a Algorithm) String() string {
+	if alg, ok := algorithms[a]; ok {
+		return alg.name
+	}
+	return fmt.Sprintf("algorithm(%d)", uint8(a))
+}
+
+func (a Algorithm) MarshalText() ([]byte, error) {
+	if _, ok := algorithms[a]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrAlgorithm, a)
+	}
+	return []byte(a.String()), nil
+}
+
+func (a *Algorithm) UnmarshalText(d []byte) (err error) {
+	*a, err = ParseAlgorithm(string(d))
+	return err
+}
+
+// NewPubKey returns the algorithm-tagged public key of the raw key.
+func NewPubKey(alg Algorithm, raw []byte) PubKey {
+	if alg == Ed25519 {
+		return append(PubKey(nil), raw...)
+	}
+	return append(PubKey{byte(alg)}, raw...)
+}
+
+// ParsePubKey returns the algorithm and the raw key of the algorithm-tagged
+// pubKey. It returns ErrAlgorithm if the algorithm isn't registered or the
+// key has wrong size for it.
+func ParsePubKey(pubKey PubKey) (alg Algorithm, raw []byte, err error) {
+	if len(pubKey) == ed25519.PublicKeySize {
+		return Ed25519, pubKey, nil
+	}
+	if len(pubKey) == 0 {
+		return 0, nil, fmt.Errorf("%w: empty key", ErrAlgorithm)
+	}
+	alg, raw = Algorithm(pubKey[0]), pubKey[1:]
+	a, ok := algorithms[alg]
+	if !ok || alg == Ed25519 || len(raw) != a.PubKeySize() {
+		return 0, nil, fmt.Errorf("%w: %v key of %d bytes",
+			ErrAlgorithm, alg, len(pubKey))
+	}
+	return alg, raw, nil
+}
+
+// GenerateKey returns a new random key of the algorithm.
+func GenerateKey(alg Algorithm) (Key, error) {
+	a, ok := algorithms[alg]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %v", ErrAlgorithm, alg)
+	}
+	return NewKeyFromSecret(alg, RandSlice(a.SecretSize()))
+}
+
+// NewKeyFromSecret returns the key of the algorithm's secret, see
+// Key.Secret.
+func NewKeyFromSecret(alg Algorithm, secret []byte) (Key, error) {
+	a, ok := algorithms[alg]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %v", ErrAlgorithm, alg)
+	}
+	priv, pub, err := a.NewKey(secret)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{PrivKey: priv, PubKey: NewPubKey(alg, pub)}, nil
+}
+
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) PubKeySize() int { return ed25519.PublicKeySize }
+
+func (ed25519Scheme) SecretSize() int { return ed25519.SeedSize }
+
+func (ed25519Scheme) NewKey(secret []byte) (priv, pub []byte, err error) {
+	if len(secret) != ed25519.SeedSize {
+		return nil, nil, fmt.Errorf("%w: ed25519 seed of %d bytes",
+			ErrAlgorithm, len(secret))
+	}
+	k := ed25519.NewKeyFromSeed(secret)
+	return k, k.Public().(ed25519.PublicKey), nil
+}
+
+func (ed25519Scheme) Secret(priv []byte) []byte {
+	return ed25519.PrivateKey(priv).Seed()
+}
+
+func (ed25519Scheme) Sign(priv, msg []byte) (Signature, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: ed25519 private key", ErrAlgorithm)
+	}
+	return ed25519.Sign(priv, msg), nil
+}
+
+func (ed25519Scheme) Verify(pub, msg []byte, sig Signature) bool {
+	return len(pub) == ed25519.PublicKeySize && ed25519.Verify(pub, msg, sig)
+}
+
+const p256Size = 32
+
+type p256Scheme struct{}
+
+func (p256Scheme) PubKeySize() int { return 1 + p256Size }
+
+func (p256Scheme) SecretSize() int { return p256Size }
+
+func (p256Scheme) NewKey(secret []byte) (priv, pub []byte, err error) {
+	k, err := p256PrivateKey(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv = append([]byte(nil), secret...)
+	return priv, elliptic.MarshalCompressed(k.Curve, k.X, k.Y), nil
+}
+
+func (p256Scheme) Secret(priv []byte) []byte {
+	return append([]byte(nil), priv...)
+}
+
+func (p256Scheme) Sign(priv, msg []byte) (Signature, error) {
+	k, err := p256PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, k, h[:])
+	if err != nil {
+		return nil, err
+	}
+	if s.Cmp(p256HalfN) > 0 {
+		s.Sub(k.Curve.Params().N, s)
+	}
+	return asn1.Marshal(p256Signature{R: r, S: s})
+}
+
+func (p256Scheme) Verify(pub, msg []byte, sig Signature) bool {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), pub)
+	if x == nil {
+		return false
+	}
+	var s p256Signature
+	if rest, err := asn1.Unmarshal(sig, &s); err != nil || len(rest) != 0 {
+		return false
+	}
+	// re-encoding rejects the other BER encodings of the same signature
+	canonical, err := asn1.Marshal(s)
+	if err != nil || !EqualBytes(canonical, sig) ||
+		s.R.Sign() <= 0 || s.S.Sign() <= 0 || s.S.Cmp(p256HalfN) > 0 {
+		return false
+	}
+	h := sha256.Sum256(msg)
+	k := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	return ecdsa.Verify(k, h[:], s.R, s.S)
+}
+
+// p256Signature is the ASN.1 structure of the ECDSA signatures.
+type p256Signature struct {
+	R, S *big.Int
+}
+
+// p256HalfN is the upper limit of the signatures' s, see P256.
+var p256HalfN = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+
+func p256PrivateKey(secret []byte) (*ecdsa.PrivateKey, error) {
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(secret)
+	if len(secret) != p256Size || d.Sign() == 0 ||
+		d.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("%w: bad p256 secret", ErrAlgorithm)
+	}
+	k := &ecdsa.PrivateKey{D: d}
+	k.Curve = curve
+	k.X, k.Y = curve.ScalarBaseMult(secret)
+	return k, nil
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+)
+
+var msg = []byte("msg")
+
+// panics tells if f panics.
+func panics(f func()) (p bool) {
+	defer func() { p = recover() != nil }()
+	f()
+	return false
+}
+
+func TestParsePubKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	ed := NewKey()
+	alg, raw, err := ParsePubKey(ed.PubKey)
+	try.To(err)
+	assert.Equal(alg, Ed25519)
+	assert.DeepEqual(raw, ed.PubKey, "ed25519 keys aren't tagged")
+
+	p := try.To1(GenerateKey(P256))
+	assert.SLen(p.PubKey, 34)
+	alg, raw, err = ParsePubKey(p.PubKey)
+	try.To(err)
+	assert.Equal(alg, P256)
+	assert.DeepEqual(NewPubKey(alg, raw), p.PubKey)
+
+	for name, pubKey := range map[string]PubKey{
+		"empty":        {},
+		"tag only":     {byte(P256)},
+		"p256 short":   p.PubKey[:33],
+		"p256 long":    append(append(PubKey{}, p.PubKey...), 0),
+		"ed25519 tag":  append(PubKey{byte(Ed25519)}, make([]byte, 33)...),
+		"unknown tag":  append(PubKey{0x7f}, make([]byte, 33)...),
+		"ed25519 long": append(append(PubKey{}, ed.PubKey...), 0),
+	} {
+		_, _, err := ParsePubKey(pubKey)
+		assert.That(errors.Is(err, ErrAlgorithm), name)
+	}
+}
+
+type sizeScheme struct {
+	p256Scheme
+	size int
+}
+
+func (s sizeScheme) PubKeySize() int { return s.size }
+
+func TestRegister(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	n := len(algorithms)
+	assert.That(panics(func() {
+		Register(0x7e, "short", sizeScheme{size: 31})
+	}), "tagged keys would be ed25519 sized")
+	assert.That(panics(func() {
+		Register(P256, "other", p256Scheme{})
+	}), "algorithm registered twice")
+	assert.That(panics(func() {
+		Register(0x7e, "p256", p256Scheme{})
+	}), "name registered twice")
+	assert.Equal(len(algorithms), n)
+
+	_, ok := Lookup(0x7e)
+	assert.That(!ok)
+	_, err := ParseAlgorithm("p256")
+	try.To(err)
+	_, err = ParseAlgorithm("unknown")
+	assert.That(errors.Is(err, ErrAlgorithm))
+}
+
+func TestP256Secret(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	n := elliptic.P256().Params().N
+	scalar := func(x *big.Int) []byte {
+		return x.FillBytes(make([]byte, p256Size))
+	}
+	for name, secret := range map[string][]byte{
+		"zero":    make([]byte, p256Size),
+		"order":   scalar(n),
+		"order+1": scalar(new(big.Int).Add(n, big.NewInt(1))),
+		"max":     bytes.Repeat([]byte{0xff}, p256Size),
+		"short":   make([]byte, p256Size-1),
+		"long":    append(scalar(big.NewInt(1)), 0),
+		"nil":     nil,
+	} {
+		_, err := NewKeyFromSecret(P256, secret)
+		assert.That(errors.Is(err, ErrAlgorithm), name)
+	}
+
+	// the smallest and the largest secrets
+	one := big.NewInt(1)
+	for _, x := range []*big.Int{one, new(big.Int).Sub(n, one)} {
+		k := try.To1(NewKeyFromSecret(P256, scalar(x)))
+		assert.That(k.VerifySign(msg, k.Sign(msg)))
+	}
+}
+
+func TestNewKeyFromSecret(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, alg := range []Algorithm{Ed25519, P256} {
+		k := try.To1(GenerateKey(alg))
+		assert.Equal(k.Algorithm(), alg)
+		k2 := try.To1(NewKeyFromSecret(alg, k.Secret()))
+		assert.DeepEqual(k2, k)
+		assert.That(k2.VerifySign(msg, k.Sign(msg)))
+		assert.That(!k2.VerifySign([]byte("other"), k.Sign(msg)))
+	}
+
+	_, err := NewKeyFromSecret(Ed25519, make([]byte, 31))
+	assert.That(errors.Is(err, ErrAlgorithm))
+	_, err = NewKeyFromSecret(0x7f, make([]byte, 32))
+	assert.That(errors.Is(err, ErrAlgorithm))
+	_, err = GenerateKey(0x7f)
+	assert.That(errors.Is(err, ErrAlgorithm))
+}
+
+func TestVerifyGarbage(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, alg := range []Algorithm{Ed25519, P256} {
+		k := try.To1(GenerateKey(alg))
+		sig := k.Sign(msg)
+		_, raw, _ := ParsePubKey(k.PubKey)
+		garbage := [][]byte{
+			nil, {}, {0}, RandSlice(len(sig)), RandSlice(256),
+			sig[:len(sig)-1], append(append([]byte{}, sig...), 0),
+		}
+		for _, g := range garbage {
+			assert.That(!VerifySign(k.PubKey, msg, g), alg.String())
+			assert.That(!VerifySign(g, msg, sig), alg.String())
+			assert.That(!algorithms[alg].Verify(g, msg, sig), alg.String())
+			assert.That(!algorithms[alg].Verify(raw, msg, g), alg.String())
+		}
+	}
+}
+
+func TestP256LowS(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	k := try.To1(GenerateKey(P256))
+	n := elliptic.P256().Params().N
+	for i := 0; i < 32; i++ {
+		sig := k.Sign(msg)
+		var s p256Signature
+		try.To1(asn1.Unmarshal(sig, &s))
+		assert.That(s.S.Cmp(p256HalfN) <= 0, "low-S")
+		assert.That(k.VerifySign(msg, sig))
+
+		// the other valid ECDSA signature of the same message
+		high := p256Signature{R: s.R, S: new(big.Int).Sub(n, s.S)}
+		assert.That(!k.VerifySign(msg, try.To1(asn1.Marshal(high))))
+	}
+
+	// non-canonical encodings of a valid signature
+	sig := k.Sign(msg)
+	long := append([]byte{sig[0], 0x81}, sig[1:]...)
+	assert.That(!k.VerifySign(msg, long), "long form length")
+	trailing := append(append([]byte{}, sig...), 0)
+	trailing[1]++
+	assert.That(!k.VerifySign(msg, trailing), "padded sequence")
+	var s p256Signature
+	try.To1(asn1.Unmarshal(sig, &s))
+	neg := p256Signature{R: new(big.Int).Neg(s.R), S: s.S}
+	assert.That(!k.VerifySign(msg, try.To1(asn1.Marshal(neg))), "negative r")
+}
@@ -6,36 +6,133 @@ package crypto
 import (
 	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 
 	"github.com/lainio/err2/assert"
 	"github.com/lainio/err2/try"
+	"golang.org/x/crypto/hkdf"
 )
 
 type PubKey = []byte
 
+// Fingerprint returns short, printable identifier of the pubKey. It's the hex
+// encoded prefix of the pubKey's SHA-256 hash.
+func Fingerprint(pubKey PubKey) string {
+	h := sha256.Sum256(pubKey)
+	return hex.EncodeToString(h[:fingerprintLen])
+}
+
+const fingerprintLen = 8
+
+// VerifySign verifies the sig of the msg with the pubKey and the algorithm of
+// it, see Algorithm. Malformed keys and unknown algorithms are treated as
+// failed verification, i.e. the function never panics.
 func VerifySign(pubKey PubKey, msg []byte, sig Signature) bool {
-	return ed25519.Verify(pubKey, msg, sig)
+	alg, raw, err := ParsePubKey(pubKey)
+	if err != nil {
+		return false
+	}
+	return algorithms[alg].Verify(raw, msg, sig)
 }
 
-// TODO: we need a KeyHandle
+// KeyHandle hides the private key from its users. It's the only thing needed
+// for signing, i.e. inviting others to chains and responding to challenges.
+// The private key can be in memory, in encrypted file, or in a KMS.
+type KeyHandle interface {
+	PublicKey() PubKey
+	Sign(msg []byte) Signature
+}
 
-// Key is a struct for full key.
+// Key is a struct for full key. It's also the simplest in-memory KeyHandle.
+// Use Handle to get a KeyHandle that doesn't expose the private key. PubKey is
+// algorithm-tagged and PrivKey is in the format of the algorithm's Scheme.
 type Key struct {
 	PrivKey []byte
 	PubKey
 }
 
+// NewKey returns a new random ed25519 key, see GenerateKey for the other
+// algorithms.
 func NewKey() Key {
 	pub, priv := try.To2(ed25519.GenerateKey(nil))
 	return Key{PrivKey: priv, PubKey: pub}
 }
 
+// NewKeyFromSeed returns the deterministic key of the 32 byte seed.
+func NewKeyFromSeed(seed []byte) Key {
+	assert.SLen(seed, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	return Key{PrivKey: priv, PubKey: priv.Public().(ed25519.PublicKey)}
+}
+
+// Derive returns a new key of the key's algorithm deterministically derived
+// from the key with HKDF-SHA256. Different info gives unrelated keys, and the
+// derived keys cannot be linked to each other or to the key without the
+// private key.
+func (k Key) Derive(info []byte) Key {
+	return try.To1(k.TryDerive(info))
+}
+
+// TryDerive is error returning version of Derive. The secret candidates are
+// read from the HKDF output until the algorithm accepts one, e.g. the P-256
+// secrets must be less than the curve order. It returns ErrAlgorithm if the
+// key is malformed or none of the maxDeriveTries candidates is accepted.
+func (k Key) TryDerive(info []byte) (dk Key, err error) {
+	alg, _, err := ParsePubKey(k.PubKey)
+	if err != nil {
+		return dk, err
+	}
+	s := algorithms[alg].Scheme
+	r := hkdf.New(sha256.New, s.Secret(k.PrivKey), nil, info)
+	secret := make([]byte, s.SecretSize())
+	for i := 0; i < maxDeriveTries; i++ {
+		if _, err := io.ReadFull(r, secret); err != nil {
+			return dk, err
+		}
+		if dk, err = NewKeyFromSecret(alg, secret); err == nil {
+			return dk, nil
+		}
+	}
+	return dk, fmt.Errorf("%w: cannot derive %v key", ErrAlgorithm, alg)
+}
+
+// maxDeriveTries limits the secret candidates of TryDerive. A P-256 candidate
+// is rejected with probability less than 2^-32.
+const maxDeriveTries = 8
+
+// Algorithm returns the algorithm of the key. It panics if the key is
+// malformed.
+func (k Key) Algorithm() Algorithm {
+	alg, _, err := ParsePubKey(k.PubKey)
+	try.To(err)
+	return alg
+}
+
+// Secret returns the secret of the key, e.g. for backups. The key can be
+// restored from it with NewKeyFromSecret.
+func (k Key) Secret() []byte {
+	return algorithms[k.Algorithm()].Secret(k.PrivKey)
+}
+
+// Handle returns an in-memory KeyHandle of the key that doesn't expose the
+// private key.
+func (k Key) Handle() KeyHandle {
+	return memKey{key: k}
+}
+
+func (k Key) PublicKey() PubKey {
+	return k.PubKey
+}
+
 func (k Key) PubKeyEqual(pubKey PubKey) bool {
 	return EqualBytes(k.PubKey, pubKey)
 }
 
 func (k Key) Sign(h []byte) Signature {
-	return ed25519.Sign(k.PrivKey, h)
+	return try.To1(algorithms[k.Algorithm()].Sign(k.PrivKey, h))
 }
 
 func (k Key) VerifySign(msg []byte, sig Signature) bool {
@@ -62,3 +159,16 @@ func EqualBytes(a, b []byte) bool {
 	}
 	return true
 }
+
+// memKey is an in-memory KeyHandle where the private key is hidden.
+type memKey struct {
+	key Key
+}
+
+func (k memKey) PublicKey() PubKey {
+	return k.key.PubKey
+}
+
+func (k memKey) Sign(msg []byte) Signature {
+	return k.key.Sign(msg)
+}
@@ -0,0 +1,26 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+)
+
+func TestDerive(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, alg := range []Algorithm{Ed25519, P256} {
+		k := try.To1(GenerateKey(alg))
+		d1 := k.Derive([]byte("1"))
+		assert.Equal(d1.Algorithm(), alg)
+		assert.DeepEqual(k.Derive([]byte("1")), d1)
+		assert.That(!d1.PubKeyEqual(k.Derive([]byte("2")).PubKey))
+		assert.That(!d1.PubKeyEqual(k.PubKey))
+		assert.That(d1.VerifySign(msg, d1.Sign(msg)))
+	}
+
+	_, err := Key{PubKey: []byte{1, 2, 3}}.TryDerive([]byte("1"))
+	assert.That(errors.Is(err, ErrAlgorithm))
+}
@@ -0,0 +1,112 @@
+// Package did implements the Decentralized Identifiers of the chain keys for
+// the interoperability with the DID and Verifiable Credential ecosystems.
+//
+// The public keys are did:key DIDs, i.e. the multibase (base58btc) encoded
+// multicodec keys, e.g. did:key:z6Mk... for ed25519 and did:key:zDn... for
+// P-256. The Resolver returns a minimal DID Document of them.
+//
+// The did:ic method is a sketch of the chain members' DIDs:
+//
+//	did:ic:<multibase root key>:<multibase leaf key>
+//
+// The DID identifies the chain of the member in the web-of-trust of the root,
+// which can be used as an issuer or subject identifier. The Resolver finds the
+// chain from a chain store, e.g. package store, and verifies it before it
+// returns the DID Document of the leaf key. Note that a key rotation gives a
+// new DID, and the DIDs reveal the root and the leaf keys, see chain.Pseudonym.
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+const (
+	prefix    = "did:"
+	methodKey = "key"
+	methodIC  = "ic"
+)
+
+var (
+	ErrKey     = errors.New("bad multibase key")
+	ErrDID     = errors.New("malformed DID")
+	ErrMethod  = errors.New("unsupported DID method")
+	ErrInvalid = errors.New("chain of DID doesn't verify")
+)
+
+// KeyDID returns the did:key DID of the public key.
+func KeyDID(pubKey crypto.PubKey) (string, error) {
+	mb, err := Multibase(pubKey)
+	if err != nil {
+		return "", err
+	}
+	return prefix + methodKey + ":" + mb, nil
+}
+
+// ParseKeyDID returns the public key of the did:key DID.
+func ParseKeyDID(did string) (crypto.PubKey, error) {
+	method, ids, err := split(did)
+	if err != nil {
+		return nil, err
+	}
+	if method != methodKey || len(ids) != 1 {
+		return nil, fmt.Errorf("%w: %q isn't did:key", ErrDID, did)
+	}
+	return ParseMultibase(ids[0])
+}
+
+// ICDID returns the did:ic DID of the chain's leaf member.
+func ICDID(c chain.Chain) (string, error) {
+	if c.Len() == 0 {
+		return "", chain.ErrEmptyChain
+	}
+	root, err := Multibase(c.Blocks[0].InviteePubKey)
+	if err != nil {
+		return "", err
+	}
+	leaf, err := Multibase(c.LeafPubKey())
+	if err != nil {
+		return "", err
+	}
+	return prefix + methodIC + ":" + root + ":" + leaf, nil
+}
+
+// ParseICDID returns the root and the leaf keys of the did:ic DID.
+func ParseICDID(did string) (root, leaf crypto.PubKey, err error) {
+	method, ids, err := split(did)
+	if err != nil {
+		return nil, nil, err
+	}
+	if method != methodIC || len(ids) != 2 {
+		return nil, nil, fmt.Errorf("%w: %q isn't did:ic", ErrDID, did)
+	}
+	if root, err = ParseMultibase(ids[0]); err != nil {
+		return nil, nil, err
+	}
+	if leaf, err = ParseMultibase(ids[1]); err != nil {
+		return nil, nil, err
+	}
+	return root, leaf, nil
+}
+
+// split returns the method and the colon separated method-specific IDs of the
+// DID. DID URLs, i.e. paths, queries and fragments, aren't accepted.
+func split(did string) (method string, ids []string, err error) {
+	if !strings.HasPrefix(did, prefix) || strings.ContainsAny(did, "/?#") {
+		return "", nil, fmt.Errorf("%w: %q", ErrDID, did)
+	}
+	parts := strings.Split(did[len(prefix):], ":")
+	for _, p := range parts {
+		if p == "" {
+			return "", nil, fmt.Errorf("%w: %q", ErrDID, did)
+		}
+	}
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("%w: %q", ErrDID, did)
+	}
+	return parts[0], parts[1:], nil
+}
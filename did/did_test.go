@@ -0,0 +1,71 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+var (
+	rootKey, aliceKey crypto.Key
+	aliceChain        chain.Chain
+)
+
+func init() {
+	rootKey = crypto.NewKey()
+	aliceKey = try.To1(crypto.GenerateKey(crypto.P256))
+
+	root := chain.NewRootChain(rootKey.PubKey)
+	aliceChain = root.Invite(rootKey, aliceKey.PubKey, chain.MemberPosition)
+}
+
+func TestKeyDID(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	did := try.To1(KeyDID(rootKey.PubKey))
+	assert.That(strings.HasPrefix(did, "did:key:z6Mk"))
+	assert.DeepEqual(try.To1(ParseKeyDID(did)), rootKey.PubKey)
+
+	did = try.To1(KeyDID(aliceKey.PubKey))
+	assert.That(strings.HasPrefix(did, "did:key:zDn"))
+	assert.DeepEqual(try.To1(ParseKeyDID(did)), aliceKey.PubKey)
+
+	for _, s := range []string{
+		"", "did:key", "did:key:", "key:" + did[8:], did + "#frag",
+		did + "/path", "did:web:example.com",
+	} {
+		_, err := ParseKeyDID(s)
+		assert.That(errors.Is(err, ErrDID), s)
+	}
+	_, err := ParseKeyDID("did:key:z6Mkxxx")
+	assert.That(errors.Is(err, ErrKey))
+}
+
+func TestICDID(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	did := try.To1(ICDID(aliceChain))
+	assert.That(strings.HasPrefix(did, "did:ic:z6Mk"))
+	root, leaf := try.To2(ParseICDID(did))
+	assert.DeepEqual(root, rootKey.PubKey)
+	assert.DeepEqual(leaf, aliceKey.PubKey)
+
+	// rotation gives a new DID in the same web-of-trust
+	rotated := aliceChain.Rotate(aliceKey, crypto.NewKey().PubKey)
+	did2 := try.To1(ICDID(rotated))
+	assert.NotEqual(did2, did)
+	root2, _ := try.To2(ParseICDID(did2))
+	assert.DeepEqual(root2, root)
+
+	_, err := ICDID(chain.Nil)
+	assert.That(errors.Is(err, chain.ErrEmptyChain))
+	_, _, err = ParseICDID(try.To1(KeyDID(rootKey.PubKey)))
+	assert.That(errors.Is(err, ErrDID))
+	_, _, err = ParseICDID("did:ic:z6Mk:z6Mk")
+	assert.That(errors.Is(err, ErrKey))
+}
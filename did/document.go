@@ -0,0 +1,123 @@
+package did
+
+import (
+	"fmt"
+
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+// JSON-LD contexts of the DID Documents.
+const (
+	contextDID      = "https://www.w3.org/ns/did/v1"
+	contextMultikey = "https://w3id.org/security/multikey/v1"
+)
+
+// typeMultikey is the verification method type of the multibase keys.
+const typeMultikey = "Multikey"
+
+// Document is a minimal DID Document with a single verification method which
+// can be used for all the verification relationships.
+type Document struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	AlsoKnownAs        []string             `json:"alsoKnownAs,omitempty"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+
+	Authentication       []string `json:"authentication"`
+	AssertionMethod      []string `json:"assertionMethod"`
+	CapabilityInvocation []string `json:"capabilityInvocation"`
+	CapabilityDelegation []string `json:"capabilityDelegation"`
+}
+
+// VerificationMethod is a public key of the Document.
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+}
+
+// PubKey returns the public key of the verification method.
+func (vm VerificationMethod) PubKey() (crypto.PubKey, error) {
+	return ParseMultibase(vm.PublicKeyMultibase)
+}
+
+// ChainGetter finds the chains of the root and leaf keys, e.g. store.Store.
+type ChainGetter interface {
+	Get(root, leaf crypto.PubKey) (chain.Chain, error)
+}
+
+// Resolver resolves the DIDs to their DID Documents. The zero value resolves
+// only did:key DIDs.
+type Resolver struct {
+	// Chains is needed for did:ic.
+	Chains ChainGetter
+
+	// Revocations are checked for the did:ic chains.
+	Revocations chain.RevocationSet
+}
+
+// Resolve returns the DID Document of the did. The errors are ErrDID,
+// ErrMethod and ErrKey for the DID, the errors of the Chains for did:ic, and
+// ErrInvalid if the chain of the did:ic doesn't verify.
+func (r Resolver) Resolve(did string) (doc Document, err error) {
+	method, _, err := split(did)
+	if err != nil {
+		return doc, err
+	}
+	switch method {
+	case methodKey:
+		pubKey, err := ParseKeyDID(did)
+		if err != nil {
+			return doc, err
+		}
+		return newDocument(did, pubKey), nil
+	case methodIC:
+		if r.Chains == nil {
+			return doc, fmt.Errorf("%w: no chains for did:ic", ErrMethod)
+		}
+		root, leaf, err := ParseICDID(did)
+		if err != nil {
+			return doc, err
+		}
+		c, err := r.Chains.Get(root, leaf)
+		if err != nil {
+			return doc, err
+		}
+		if err := c.TryVerifyWith(r.Revocations); err != nil {
+			return doc, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if id, err := ICDID(c); err != nil || id != did {
+			return doc, fmt.Errorf("%w: other chain", ErrInvalid)
+		}
+		doc = newDocument(did, leaf)
+		doc.AlsoKnownAs = []string{try.To1(KeyDID(leaf))}
+		return doc, nil
+	default:
+		return doc, fmt.Errorf("%w: %q", ErrMethod, method)
+	}
+}
+
+// newDocument returns the document of the did with the pubKey, which must have
+// a multicodec, see Multibase.
+func newDocument(did string, pubKey crypto.PubKey) Document {
+	mb := try.To1(Multibase(pubKey))
+	vmID := did + "#" + mb
+	refs := []string{vmID}
+	return Document{
+		Context: []string{contextDID, contextMultikey},
+		ID:      did,
+		VerificationMethod: []VerificationMethod{{
+			ID:                 vmID,
+			Type:               typeMultikey,
+			Controller:         did,
+			PublicKeyMultibase: mb,
+		}},
+		Authentication:       refs,
+		AssertionMethod:      refs,
+		CapabilityInvocation: refs,
+		CapabilityDelegation: refs,
+	}
+}
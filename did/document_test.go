@@ -0,0 +1,72 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/store"
+)
+
+func TestResolveKey(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	did := try.To1(KeyDID(aliceKey.PubKey))
+	doc := try.To1(Resolver{}.Resolve(did))
+	assert.Equal(doc.ID, did)
+	assert.SLen(doc.VerificationMethod, 1)
+	vm := doc.VerificationMethod[0]
+	assert.Equal(vm.Type, "Multikey")
+	assert.Equal(vm.Controller, did)
+	assert.Equal(vm.ID, did+"#"+vm.PublicKeyMultibase)
+	assert.DeepEqual(try.To1(vm.PubKey()), aliceKey.PubKey)
+	assert.DeepEqual(doc.Authentication, []string{vm.ID})
+	assert.DeepEqual(doc.AssertionMethod, []string{vm.ID})
+
+	var m map[string]any
+	try.To(json.Unmarshal(try.To1(json.Marshal(doc)), &m))
+	assert.DeepEqual(m["@context"], []any{
+		"https://www.w3.org/ns/did/v1",
+		"https://w3id.org/security/multikey/v1",
+	})
+	assert.DeepEqual(m["id"], did)
+	_, ok := m["alsoKnownAs"]
+	assert.That(!ok)
+
+	_, err := Resolver{}.Resolve("did:web:example.com")
+	assert.That(errors.Is(err, ErrMethod))
+	_, err = Resolver{}.Resolve(try.To1(ICDID(aliceChain)))
+	assert.That(errors.Is(err, ErrMethod), "no chains")
+	_, err = Resolver{}.Resolve("did:key")
+	assert.That(errors.Is(err, ErrDID))
+}
+
+func TestResolveIC(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s := try.To1(store.OpenDir(t.TempDir()))
+	defer s.Close()
+	try.To(s.Put(aliceChain))
+	r := Resolver{Chains: s}
+
+	did := try.To1(ICDID(aliceChain))
+	doc := try.To1(r.Resolve(did))
+	assert.Equal(doc.ID, did)
+	assert.DeepEqual(try.To1(doc.VerificationMethod[0].PubKey()), aliceKey.PubKey)
+	assert.DeepEqual(doc.AlsoKnownAs, []string{try.To1(KeyDID(aliceKey.PubKey))})
+
+	// unknown member
+	bob := chain.NewRootChain(rootKey.PubKey).
+		Invite(rootKey, crypto.NewKey().PubKey, chain.MemberPosition)
+	_, err := r.Resolve(try.To1(ICDID(bob)))
+	assert.That(errors.Is(err, store.ErrNotFound))
+
+	// revoked member
+	r.Revocations = chain.NewRevocationSet(chain.Revoke(rootKey, aliceChain))
+	_, err = r.Resolve(did)
+	assert.That(errors.Is(err, ErrInvalid))
+}
@@ -0,0 +1,128 @@
+package did
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/lainio/ic/crypto"
+)
+
+const (
+	// base58Alphabet is the Bitcoin alphabet of base58btc.
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// base58btcPrefix is the multibase prefix of base58btc.
+	base58btcPrefix = 'z'
+)
+
+// multicodecs of the public keys, see the multicodec table.
+var codecs = map[crypto.Algorithm]uint64{
+	crypto.Ed25519: 0xed,   // ed25519-pub
+	crypto.P256:    0x1200, // p256-pub, compressed
+}
+
+// Multibase returns the public key as a multibase (base58btc) encoded
+// multicodec key, i.e. the publicKeyMultibase of the verification methods. It
+// returns ErrKey if the key's algorithm has no multicodec.
+func Multibase(pubKey crypto.PubKey) (string, error) {
+	alg, raw, err := crypto.ParsePubKey(pubKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrKey, err)
+	}
+	codec, ok := codecs[alg]
+	if !ok {
+		return "", fmt.Errorf("%w: no multicodec for %v", ErrKey, alg)
+	}
+	d := binary.AppendUvarint(nil, codec)
+	return string(base58btcPrefix) + base58Encode(append(d, raw...)), nil
+}
+
+// ParseMultibase decodes the multibase encoded multicodec key, see Multibase.
+// Only base58btc is supported. Decoding errors are ErrKey.
+func ParseMultibase(s string) (crypto.PubKey, error) {
+	if len(s) == 0 || s[0] != base58btcPrefix {
+		return nil, fmt.Errorf("%w: not base58btc multibase", ErrKey)
+	}
+	d, err := base58Decode(s[1:])
+	if err != nil {
+		return nil, err
+	}
+	codec, n := binary.Uvarint(d)
+	if n <= 0 || n != len(binary.AppendUvarint(nil, codec)) {
+		return nil, fmt.Errorf("%w: bad multicodec", ErrKey)
+	}
+	for alg, c := range codecs {
+		if c != codec {
+			continue
+		}
+		pubKey := crypto.NewPubKey(alg, d[n:])
+		if a, _, err := crypto.ParsePubKey(pubKey); err != nil || a != alg {
+			return nil, fmt.Errorf("%w: malformed %v key", ErrKey, alg)
+		}
+		return pubKey, nil
+	}
+	return nil, fmt.Errorf("%w: unknown multicodec 0x%x", ErrKey, codec)
+}
+
+// base58Encode encodes the d with base58btc. The leading zero bytes are
+// encoded as '1's.
+func base58Encode(d []byte) string {
+	zeros := 0
+	for zeros < len(d) && d[zeros] == 0 {
+		zeros++
+	}
+	// log(256)/log(58) < 1.37
+	digits := make([]byte, 0, len(d)*137/100+1)
+	for _, b := range d[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	var s strings.Builder
+	s.Grow(zeros + len(digits))
+	for i := 0; i < zeros; i++ {
+		s.WriteByte(base58Alphabet[0])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		s.WriteByte(base58Alphabet[digits[i]])
+	}
+	return s.String()
+}
+
+// base58Decode decodes the base58btc string s. Errors are ErrKey.
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// log(58)/log(256) < 0.74
+	bytes := make([]byte, 0, len(s)*74/100+1)
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("%w: bad base58 character %q", ErrKey, s[i])
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	d := make([]byte, zeros, zeros+len(bytes))
+	for i := len(bytes) - 1; i >= 0; i-- {
+		d = append(d, bytes[i])
+	}
+	return d, nil
+}
@@ -0,0 +1,70 @@
+package did
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestBase58(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for h, s := range map[string]string{
+		"":                         "",
+		"00":                       "1",
+		"0000287fb4cd":             "11233QC4",
+		"48656c6c6f20576f726c6421": "2NEpo7TZRRrLZSi2U", // Hello World!
+		"ff":                       "5Q",
+	} {
+		d := try.To1(hex.DecodeString(h))
+		assert.Equal(base58Encode(d), s, h)
+		assert.Equal(hex.EncodeToString(try.To1(base58Decode(s))), h, s)
+	}
+	_, err := base58Decode("0OIl")
+	assert.That(errors.Is(err, ErrKey))
+}
+
+func TestMultibase(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	key := crypto.NewKey()
+	mb := try.To1(Multibase(key.PubKey))
+	assert.That(strings.HasPrefix(mb, "z6Mk"), "ed25519 multikeys start z6Mk")
+	assert.DeepEqual(try.To1(ParseMultibase(mb)), key.PubKey)
+
+	p256 := try.To1(crypto.GenerateKey(crypto.P256))
+	mb = try.To1(Multibase(p256.PubKey))
+	assert.That(strings.HasPrefix(mb, "zDn"), "P-256 multikeys start zDn")
+	assert.DeepEqual(try.To1(ParseMultibase(mb)), p256.PubKey)
+
+	_, err := Multibase([]byte{1, 2, 3})
+	assert.That(errors.Is(err, ErrKey))
+}
+
+func TestParseMultibaseFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	ed := try.To1(hex.DecodeString("ed01"))
+	p256 := try.To1(hex.DecodeString("8024"))
+	for name, d := range map[string][]byte{
+		"short ed25519":        append(ed, make([]byte, 31)...),
+		"long ed25519":         append(ed, make([]byte, 33)...),
+		"p256 as ed25519":      append(ed, append([]byte{1}, make([]byte, 33)...)...),
+		"short p256":           append(p256, make([]byte, 32)...),
+		"unknown codec":        append([]byte{0xe7, 0x01}, make([]byte, 33)...),
+		"non-minimal varint":   append([]byte{0xed, 0x81, 0x00}, make([]byte, 32)...),
+		"truncated multicodec": {0xed},
+	} {
+		_, err := ParseMultibase("z" + base58Encode(d))
+		assert.That(errors.Is(err, ErrKey), name)
+	}
+	for _, s := range []string{"", "z", "u7QE", "z0"} {
+		_, err := ParseMultibase(s)
+		assert.That(errors.Is(err, ErrKey), s)
+	}
+}
@@ -2,6 +2,18 @@ module github.com/lainio/ic
 
 go 1.19
 
-require github.com/lainio/err2 v0.9.52
+require (
+	github.com/lainio/err2 v0.9.52
+	golang.org/x/crypto v0.11.0
+	google.golang.org/grpc v1.57.0
+	google.golang.org/protobuf v1.31.0
+)
 
-require golang.org/x/exp v0.0.0-20230105202349-8879d0199aa3 // indirect
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/exp v0.0.0-20230105202349-8879d0199aa3 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+)
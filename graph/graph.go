@@ -0,0 +1,278 @@
+// Package graph implements a trust graph index over many invitation chains.
+// The verified chains are ingested to a forest where every member of a
+// web-of-trust is a vertex, identified by the hash of the block that invited
+// it, and its inviter is the parent. Because the chains share their prefixes,
+// every member is stored only once.
+//
+// The queries walk only the part of the tree they need: Ancestors and
+// Distance are proportional to the depth, InvitedBy to the number of the
+// invitees, and Subtree and Within to the size of the result. None of them
+// depend on the number of chains ingested.
+package graph
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+var ErrInvalid = errors.New("chain doesn't verify")
+
+// ID identifies a member. It's the hash of the block where the member was
+// invited, or the root block.
+type ID [sha256.Size]byte
+
+func (id ID) String() string {
+	return hex.EncodeToString(id[:8])
+}
+
+// Member is a member of a web-of-trust.
+type Member struct {
+	ID       ID
+	Inviter  ID // zero ID for the roots
+	Root     ID
+	PubKey   crypto.PubKey // the current key, i.e. after the key rotations
+	Position chain.Position
+	Depth    int // distance from the root
+}
+
+// IsRoot tells if the member is the root of its web-of-trust.
+func (m Member) IsRoot() bool {
+	return m.Depth == 0
+}
+
+type vertex struct {
+	Member
+	parent    *vertex
+	children  []*vertex
+	rotations int
+}
+
+// Graph is the trust graph index. It's safe for concurrent use.
+type Graph struct {
+	mu      sync.RWMutex
+	members map[ID]*vertex
+	byKey   map[string]*vertex // the keys of the members, also rotated
+}
+
+// New returns an empty graph.
+func New() *Graph {
+	return &Graph{
+		members: make(map[ID]*vertex),
+		byKey:   make(map[string]*vertex),
+	}
+}
+
+// Add ingests the chain to the graph. The chain must verify. Adding the same
+// members again is a no-op, but a chain with more key rotations updates the
+// member's current key. It returns the ID of the chain's leaf member.
+func (g *Graph) Add(c chain.Chain) (id ID, err error) {
+	if err := c.TryVerify(); err != nil {
+		return id, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var parent *vertex
+	for i := 0; i < c.Len(); {
+		b := c.Blocks[i]
+		// rotation and pseudonym blocks belong to the member before them
+		j := i + 1
+		for j < c.Len() && c.Blocks[j].Kind != chain.KindInvite {
+			j++
+		}
+		copy(id[:], b.Hash())
+		v, ok := g.members[id]
+		if !ok {
+			v = &vertex{Member: Member{
+				ID:       id,
+				Root:     id,
+				PubKey:   b.InviteePubKey,
+				Position: b.Position,
+			}, parent: parent}
+			if parent != nil {
+				v.Inviter = parent.ID
+				v.Root = parent.Root
+				v.Depth = parent.Depth + 1
+				parent.children = append(parent.children, v)
+			}
+			g.members[id] = v
+		}
+		// the member's current key is its last rotated key. The pseudonym
+		// keys and their rotations aren't indexed, or Lookup would link the
+		// pseudonyms to the member.
+		rotations, last := 0, i
+		for k := i; k < j; k++ {
+			if c.Blocks[k].Kind == chain.KindPseudonym {
+				break
+			}
+			g.byKey[string(c.Blocks[k].InviteePubKey)] = v
+			if c.Blocks[k].Kind == chain.KindRotation && last == k-1 {
+				rotations, last = rotations+1, k
+			}
+		}
+		if rotations > v.rotations {
+			v.rotations = rotations
+			v.PubKey = c.Blocks[last].InviteePubKey
+		}
+		parent = v
+		i = j
+	}
+	return id, nil
+}
+
+// Len returns the number of members in the graph.
+func (g *Graph) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return len(g.members)
+}
+
+// Member returns the member of the id.
+func (g *Graph) Member(id ID) (Member, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.members[id]
+	if !ok {
+		return Member{}, false
+	}
+	return v.Member, true
+}
+
+// Lookup returns the member of the pubKey. The rotated keys are found as well,
+// but the pseudonym keys aren't, see chain.Chain.Pseudonym.
+// Note that the same key can be a member of many webs-of-trust, in which case
+// the member of the last ingested chain is returned.
+func (g *Graph) Lookup(pubKey crypto.PubKey) (Member, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.byKey[string(pubKey)]
+	if !ok {
+		return Member{}, false
+	}
+	return v.Member, true
+}
+
+// Ancestors returns the inviters of the member, the closest first and the root
+// last.
+func (g *Graph) Ancestors(id ID) []Member {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.members[id]
+	if !ok {
+		return nil
+	}
+	ms := make([]Member, 0, v.Depth)
+	for p := v.parent; p != nil; p = p.parent {
+		ms = append(ms, p.Member)
+	}
+	return ms
+}
+
+// InvitedBy returns the members the member invited, in the order they were
+// ingested.
+func (g *Graph) InvitedBy(id ID) []Member {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.members[id]
+	if !ok {
+		return nil
+	}
+	ms := make([]Member, 0, len(v.children))
+	for _, c := range v.children {
+		ms = append(ms, c.Member)
+	}
+	return ms
+}
+
+// Subtree returns all the members invited by the member directly or
+// indirectly, in depth-first pre-order. The member itself isn't included.
+func (g *Graph) Subtree(id ID) []Member {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.members[id]
+	if !ok {
+		return nil
+	}
+	var ms []Member
+	var walk func(v *vertex)
+	walk = func(v *vertex) {
+		for _, c := range v.children {
+			ms = append(ms, c.Member)
+			walk(c)
+		}
+	}
+	walk(v)
+	return ms
+}
+
+// Distance returns the number of the invitation links between the members in
+// the tree. It equals to chain.Hops unless one of the members is an indirect
+// inviter of the other: chain.Hops routes thru the common inviter of both but
+// Distance goes straight down the tree. If the members aren't in the same
+// web-of-trust it returns chain.NotConnected.
+func (g *Graph) Distance(a, b ID) int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	va, vb := g.members[a], g.members[b]
+	if va == nil || vb == nil || va.Root != vb.Root {
+		return chain.NotConnected
+	}
+	hops := 0
+	for va.Depth > vb.Depth {
+		va, hops = va.parent, hops+1
+	}
+	for vb.Depth > va.Depth {
+		vb, hops = vb.parent, hops+1
+	}
+	for va != vb {
+		va, vb, hops = va.parent, vb.parent, hops+2
+	}
+	return hops
+}
+
+// Within returns the members which are at most n hops from the member, closest
+// first. The member itself isn't included.
+func (g *Graph) Within(id ID, n int) []Member {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.members[id]
+	if !ok {
+		return nil
+	}
+	var ms []Member
+	seen := map[*vertex]bool{v: true}
+	level := []*vertex{v}
+	for hops := 0; hops < n && len(level) > 0; hops++ {
+		var next []*vertex
+		for _, v := range level {
+			neighbours := v.children
+			if v.parent != nil {
+				neighbours = append([]*vertex{v.parent}, neighbours...)
+			}
+			for _, nb := range neighbours {
+				if !seen[nb] {
+					seen[nb] = true
+					ms = append(ms, nb.Member)
+					next = append(next, nb)
+				}
+			}
+		}
+		level = next
+	}
+	return ms
+}
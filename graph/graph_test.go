@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+type entity struct {
+	crypto.Key
+	chain.Chain
+}
+
+func newEntity(inviter entity) (e entity) {
+	e.Key = crypto.NewKey()
+	e.Chain = inviter.Invite(inviter.Key, e.PubKey, inviter.Position())
+	return e
+}
+
+func newRoot() (e entity) {
+	e.Key = crypto.NewKey()
+	e.Chain = chain.NewRootChain(e.PubKey)
+	return e
+}
+
+// root -> alice -> carol -> erin
+// root -> alice -> dave
+// root -> bob
+// root2 -> frank
+var root, alice, bob, carol, dave, erin, root2, frank entity
+
+func init() {
+	root = newRoot()
+	alice = newEntity(root)
+	bob = newEntity(root)
+	carol = newEntity(alice)
+	dave = newEntity(alice)
+	erin = newEntity(carol)
+	root2 = newRoot()
+	frank = newEntity(root2)
+}
+
+func newGraph() (*Graph, map[string]ID) {
+	g := New()
+	ids := make(map[string]ID)
+	// the order of the invitees depends on the order of the chains added
+	for _, e := range []struct {
+		name string
+		entity
+	}{
+		{"alice", alice}, {"bob", bob}, {"carol", carol}, {"dave", dave},
+		{"erin", erin}, {"frank", frank},
+	} {
+		ids[e.name] = try.To1(g.Add(e.Chain))
+	}
+	ids["root"] = try.To1(g.Add(root.Chain))
+	ids["root2"] = try.To1(g.Add(root2.Chain))
+	return g, ids
+}
+
+func names(ms []Member, ids map[string]ID) []string {
+	byID := make(map[ID]string)
+	for name, id := range ids {
+		byID[id] = name
+	}
+	ns := make([]string, 0, len(ms))
+	for _, m := range ms {
+		ns = append(ns, byID[m.ID])
+	}
+	return ns
+}
+
+func TestAdd(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	g, ids := newGraph()
+	assert.Equal(g.Len(), 8, "shared prefixes are stored once")
+
+	m, ok := g.Lookup(carol.PubKey)
+	assert.That(ok)
+	assert.Equal(m.ID, ids["carol"])
+	assert.Equal(m.Inviter, ids["alice"])
+	assert.Equal(m.Root, ids["root"])
+	assert.Equal(m.Depth, 2)
+	assert.ThatNot(m.IsRoot())
+	r, _ := g.Member(ids["root"])
+	assert.That(r.IsRoot())
+
+	broken := bob.Clone()
+	broken.Blocks[1].InvitersSignature[0] ^= 0x01
+	_, err := g.Add(broken)
+	assert.That(errors.Is(err, ErrInvalid))
+}
+
+func TestQueries(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	g, ids := newGraph()
+
+	assert.DeepEqual(names(g.Ancestors(ids["erin"]), ids),
+		[]string{"carol", "alice", "root"})
+	assert.SLen(g.Ancestors(ids["root"]), 0)
+
+	assert.DeepEqual(names(g.InvitedBy(ids["alice"]), ids),
+		[]string{"carol", "dave"})
+	assert.DeepEqual(names(g.Subtree(ids["alice"]), ids),
+		[]string{"carol", "erin", "dave"})
+	assert.SLen(g.Subtree(ids["root"]), 5)
+
+	assert.DeepEqual(names(g.Within(ids["carol"], 1), ids),
+		[]string{"alice", "erin"})
+	assert.DeepEqual(names(g.Within(ids["carol"], 2), ids),
+		[]string{"alice", "erin", "root", "dave"})
+	assert.SLen(g.Within(ids["carol"], 10), 5)
+
+	var unknown ID
+	assert.SLen(g.Subtree(unknown), 0)
+	assert.SLen(g.Within(unknown, 3), 0)
+}
+
+func TestDistance(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	g, ids := newGraph()
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"alice", "root", 1},
+		{"alice", "bob", 2},
+		{"carol", "dave", 2},
+		{"erin", "dave", 3},
+		{"erin", "bob", 4},
+		{"erin", "alice", 2},
+		{"erin", "root", 3},
+		{"erin", "erin", 0},
+		{"erin", "frank", chain.NotConnected},
+	}
+	for _, tt := range tests {
+		assert.Equal(g.Distance(ids[tt.a], ids[tt.b]), tt.want,
+			"%s-%s", tt.a, tt.b)
+		assert.Equal(g.Distance(ids[tt.b], ids[tt.a]), tt.want,
+			"%s-%s", tt.b, tt.a)
+	}
+
+	// same as chain.Hops when neither is an indirect inviter of the other
+	for _, p := range [][2]entity{{carol, dave}, {erin, bob}, {erin, dave}} {
+		hops, _ := chain.Hops(p[0].Chain, p[1].Chain)
+		a, _ := g.Lookup(p[0].PubKey)
+		b, _ := g.Lookup(p[1].PubKey)
+		assert.Equal(g.Distance(a.ID, b.ID), hops)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	g, ids := newGraph()
+	newKey := crypto.NewKey()
+	rotated := carol.Rotate(carol.Key, newKey.PubKey)
+	id := try.To1(g.Add(rotated))
+	assert.Equal(id, ids["carol"], "rotation keeps the member")
+	assert.Equal(g.Len(), 8)
+
+	m, ok := g.Lookup(newKey.PubKey)
+	assert.That(ok)
+	assert.DeepEqual(m.PubKey, newKey.PubKey)
+	m, ok = g.Lookup(carol.PubKey)
+	assert.That(ok)
+	assert.Equal(m.ID, ids["carol"])
+
+	// invitee of the new key is under the same member
+	gus := rotated.Invite(newKey, crypto.NewKey().PubKey, 1)
+	gusID := try.To1(g.Add(gus))
+	assert.DeepEqual(names(g.InvitedBy(ids["carol"]), ids),
+		[]string{"erin", ""})
+	assert.Equal(g.Distance(gusID, ids["erin"]), 2)
+
+	// old chains don't roll the key back
+	try.To1(g.Add(carol.Chain))
+	m, _ = g.Member(ids["carol"])
+	assert.DeepEqual(m.PubKey, newKey.PubKey)
+}
+
+func TestPseudonym(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	g, ids := newGraph()
+	pseudoKey := crypto.NewKey()
+	pseudo := dave.Pseudonym(dave.Key, pseudoKey.PubKey)
+	id := try.To1(g.Add(pseudo))
+	assert.Equal(id, ids["dave"], "pseudonym keeps the member")
+	assert.Equal(g.Len(), 8)
+
+	// pseudonym key isn't linked to the member
+	_, ok := g.Lookup(pseudoKey.PubKey)
+	assert.That(!ok)
+	m, _ := g.Member(id)
+	assert.DeepEqual(m.PubKey, dave.PubKey)
+	assert.Equal(g.Distance(id, ids["erin"]), 3)
+
+	// rotations of the pseudonym key aren't the member's rotations
+	rotatedKey := crypto.NewKey()
+	try.To1(g.Add(pseudo.Rotate(pseudoKey, rotatedKey.PubKey)))
+	m, _ = g.Member(ids["dave"])
+	assert.DeepEqual(m.PubKey, dave.PubKey)
+	_, ok = g.Lookup(rotatedKey.PubKey)
+	assert.That(!ok)
+}
@@ -0,0 +1,128 @@
+// Package hdkey implements hierarchical deterministic derivation of the chain
+// keys. All the keys of a user are derived from a single seed with the
+// SLIP-0010 ed25519 derivation, which supports only hardened child keys. The
+// seed is backed up as a mnemonic phrase, see NewMnemonic, and a Wallet
+// regenerates every leaf key of a node.Node from it.
+//
+// The paths are written like in BIP-32, e.g. m/18755'/0'/1'. Because only the
+// hardened derivation is possible, the indexes without the ' (or h) suffix are
+// rejected.
+package hdkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lainio/ic/crypto"
+)
+
+// Hardened is the offset of the hardened child indexes.
+const Hardened uint32 = 1 << 31
+
+// masterSecret is the HMAC key of the SLIP-0010 ed25519 master key.
+const masterSecret = "ed25519 seed"
+
+// Minimum and maximum lengths of the seed in bytes, like in BIP-32.
+const (
+	MinSeedLen = 16
+	MaxSeedLen = 64
+)
+
+var (
+	ErrSeed = errors.New("bad seed length")
+	ErrPath = errors.New("bad derivation path")
+)
+
+// ExtendedKey is a node of the derivation tree: the ed25519 seed of the key
+// and the chain code of its children.
+type ExtendedKey struct {
+	seed      [32]byte
+	chainCode [32]byte
+}
+
+// NewMaster returns the master key of the seed.
+func NewMaster(seed []byte) (ExtendedKey, error) {
+	if len(seed) < MinSeedLen || len(seed) > MaxSeedLen {
+		return ExtendedKey{}, fmt.Errorf("%w: %d", ErrSeed, len(seed))
+	}
+	return newExtendedKey([]byte(masterSecret), seed), nil
+}
+
+// Child returns the hardened child i of the key. The Hardened offset is added
+// to i if it's missing.
+func (k ExtendedKey) Child(i uint32) ExtendedKey {
+	data := make([]byte, 0, 1+len(k.seed)+4)
+	data = append(data, 0x00)
+	data = append(data, k.seed[:]...)
+	data = binary.BigEndian.AppendUint32(data, i|Hardened)
+	return newExtendedKey(k.chainCode[:], data)
+}
+
+// Derive returns the descendant key of the path, e.g. m/18755'/0'/1'.
+func (k ExtendedKey) Derive(path string) (ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return ExtendedKey{}, err
+	}
+	for _, i := range indexes {
+		k = k.Child(i)
+	}
+	return k, nil
+}
+
+// Key returns the ed25519 key of the extended key.
+func (k ExtendedKey) Key() crypto.Key {
+	return crypto.NewKeyFromSeed(k.seed[:])
+}
+
+// ChainCode returns the chain code of the extended key. Together with the
+// key it's needed to derive the children, i.e. keep it secret.
+func (k ExtendedKey) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode[:]...)
+}
+
+func newExtendedKey(hmacKey, data []byte) (k ExtendedKey) {
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	copy(k.seed[:], sum[:32])
+	copy(k.chainCode[:], sum[32:])
+	return k
+}
+
+// ParsePath parses the derivation path. The indexes are returned with the
+// Hardened offset.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("%w: %q must start with m", ErrPath, path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		s := strings.TrimRight(p, "'hH")
+		if len(p)-len(s) != 1 {
+			return nil, fmt.Errorf("%w: %q isn't hardened", ErrPath, p)
+		}
+		i, err := strconv.ParseUint(s, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrPath, p, err)
+		}
+		indexes = append(indexes, uint32(i)|Hardened)
+	}
+	return indexes, nil
+}
+
+// Path returns the path string of the indexes, see ParsePath.
+func Path(indexes ...uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range indexes {
+		fmt.Fprintf(&b, "/%d'", i&^Hardened)
+	}
+	return b.String()
+}
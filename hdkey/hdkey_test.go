@@ -0,0 +1,79 @@
+package hdkey
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+)
+
+// SLIP-0010 test vector 1 for ed25519.
+var vector1 = []struct {
+	path, chainCode, priv, pub string
+}{
+	{
+		"m",
+		"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+	},
+	{
+		"m/0'",
+		"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+	},
+	{
+		"m/0'/1'",
+		"a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+	},
+}
+
+func TestVector1(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	seed := try.To1(hex.DecodeString("000102030405060708090a0b0c0d0e0f"))
+	master := try.To1(NewMaster(seed))
+	for _, v := range vector1 {
+		k := try.To1(master.Derive(v.path))
+		assert.Equal(hex.EncodeToString(k.ChainCode()), v.chainCode, v.path)
+		key := k.Key()
+		assert.Equal(hex.EncodeToString(key.PrivKey[:32]), v.priv, v.path)
+		assert.Equal(hex.EncodeToString(key.PubKey), v.pub, v.path)
+	}
+}
+
+func TestChild(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	master := try.To1(NewMaster(make([]byte, MinSeedLen)))
+	assert.DeepEqual(master.Child(1), master.Child(1|Hardened))
+	assert.DeepEqual(master.Child(1).Child(2), try.To1(master.Derive("m/1'/2h")))
+	assert.NotDeepEqual(master.Child(1), master.Child(2))
+
+	_, err := NewMaster(make([]byte, MinSeedLen-1))
+	assert.That(errors.Is(err, ErrSeed))
+	_, err = NewMaster(make([]byte, MaxSeedLen+1))
+	assert.That(errors.Is(err, ErrSeed))
+}
+
+func TestParsePath(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	indexes := try.To1(ParsePath("m/18755'/0H/12h"))
+	assert.DeepEqual(indexes, []uint32{18755 | Hardened, Hardened, 12 | Hardened})
+	assert.Equal(Path(indexes...), "m/18755'/0'/12'")
+	assert.SLen(try.To1(ParsePath("m")), 0)
+
+	for _, path := range []string{
+		"", "/0'", "x/0'", "m/0", "m/0''", "m/'", "m/-1'", "m/2147483648'",
+		"m/0'/", "m/a'",
+	} {
+		_, err := ParsePath(path)
+		assert.That(errors.Is(err, ErrPath), path)
+	}
+}
@@ -0,0 +1,134 @@
+package hdkey
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lainio/ic/crypto"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Mnemonic backup
+//
+// The mnemonic is like in BIP-39 but without a wordlist. The entropy and its
+// checksum, the first 16 bits of SHA-256 of the entropy, are written as
+// proquints, i.e. pronounceable five letter words which carry 16 bits each:
+// consonant, vowel, consonant, vowel, consonant. 128 bits of entropy give
+// nine words, e.g. "lusab babad gutih tugad ...". The seed is stretched from
+// the mnemonic like in BIP-39: PBKDF2-HMAC-SHA512 with 2048 iterations and
+// the salt "mnemonic" || passphrase.
+
+const (
+	consonants = "bdfghjklmnprstvz"
+	vowels     = "aiou"
+	wordLen    = 5
+
+	seedIterations = 2048
+	seedSaltPrefix = "mnemonic"
+)
+
+// Supported entropy sizes in bytes.
+const (
+	EntropySize128 = 16
+	EntropySize256 = 32
+)
+
+var ErrMnemonic = errors.New("bad mnemonic")
+
+// NewMnemonic returns a new random mnemonic of the entropy size, which must be
+// EntropySize128 or EntropySize256.
+func NewMnemonic(size int) (string, error) {
+	if size != EntropySize128 && size != EntropySize256 {
+		return "", fmt.Errorf("%w: entropy size %d", ErrMnemonic, size)
+	}
+	return EntropyToMnemonic(crypto.RandSlice(size))
+}
+
+// EntropyToMnemonic returns the mnemonic of the entropy, see NewMnemonic.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) != EntropySize128 && len(entropy) != EntropySize256 {
+		return "", fmt.Errorf("%w: entropy size %d", ErrMnemonic, len(entropy))
+	}
+	d := append(append([]byte(nil), entropy...), checksum(entropy)...)
+	words := make([]string, 0, len(d)/2)
+	for i := 0; i < len(d); i += 2 {
+		words = append(words, proquint(uint16(d[i])<<8|uint16(d[i+1])))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes the mnemonic and checks its checksum. The words
+// can be separated with any white space or hyphens, and case is ignored.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := words(mnemonic)
+	n := len(words)
+	if n != EntropySize128/2+1 && n != EntropySize256/2+1 {
+		return nil, fmt.Errorf("%w: %d words", ErrMnemonic, n)
+	}
+	d := make([]byte, 0, 2*n)
+	for _, w := range words {
+		v, ok := parseProquint(w)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrMnemonic, w)
+		}
+		d = append(d, byte(v>>8), byte(v))
+	}
+	entropy, sum := d[:len(d)-2], d[len(d)-2:]
+	if !crypto.EqualBytes(checksum(entropy), sum) {
+		return nil, fmt.Errorf("%w: checksum", ErrMnemonic)
+	}
+	return entropy, nil
+}
+
+// MnemonicToSeed checks the mnemonic and returns its 64 byte seed. The
+// optional passphrase gives a different seed for the same mnemonic.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(words(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte(seedSaltPrefix+passphrase),
+		seedIterations, MaxSeedLen, sha512.New), nil
+}
+
+func words(mnemonic string) []string {
+	return strings.FieldsFunc(strings.ToLower(mnemonic), func(r rune) bool {
+		return r == '-' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+func checksum(entropy []byte) []byte {
+	sum := sha256.Sum256(entropy)
+	return sum[:2]
+}
+
+func proquint(v uint16) string {
+	return string([]byte{
+		consonants[v>>12&0x0f],
+		vowels[v>>10&0x03],
+		consonants[v>>6&0x0f],
+		vowels[v>>4&0x03],
+		consonants[v&0x0f],
+	})
+}
+
+func parseProquint(w string) (v uint16, ok bool) {
+	if len(w) != wordLen {
+		return 0, false
+	}
+	for i := 0; i < wordLen; i++ {
+		alphabet, bits := consonants, 4
+		if i%2 == 1 {
+			alphabet, bits = vowels, 2
+		}
+		j := strings.IndexByte(alphabet, w[i])
+		if j < 0 {
+			return 0, false
+		}
+		v = v<<bits | uint16(j)
+	}
+	return v, true
+}
@@ -0,0 +1,83 @@
+package hdkey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestProquint(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// examples of the proquint specification, 127.0.0.1 and 63.84.220.193
+	for v, w := range map[uint16]string{
+		0x7f00: "lusab", 0x0001: "babad", 0x3f54: "gutih", 0xdcc1: "tugad",
+	} {
+		assert.Equal(proquint(v), w)
+		pv, ok := parseProquint(w)
+		assert.That(ok)
+		assert.Equal(pv, v)
+	}
+	for _, w := range []string{"", "lusa", "lusabb", "lusax", "aaaaa", "bbbbb"} {
+		_, ok := parseProquint(w)
+		assert.That(!ok, w)
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	for _, size := range []int{EntropySize128, EntropySize256} {
+		m := try.To1(NewMnemonic(size))
+		assert.SLen(strings.Fields(m), size/2+1)
+		entropy := try.To1(MnemonicToEntropy(m))
+		assert.SLen(entropy, size)
+		assert.Equal(try.To1(EntropyToMnemonic(entropy)), m)
+	}
+	_, err := NewMnemonic(24)
+	assert.That(errors.Is(err, ErrMnemonic))
+	_, err = EntropyToMnemonic(make([]byte, 8))
+	assert.That(errors.Is(err, ErrMnemonic))
+}
+
+func TestMnemonicToEntropyFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	entropy := []byte("0123456789abcdef")
+	m := try.To1(EntropyToMnemonic(entropy))
+	words := strings.Fields(m)
+
+	_, err := MnemonicToEntropy(strings.Join(words[1:], " "))
+	assert.That(errors.Is(err, ErrMnemonic), "too few words")
+
+	swapped := append([]string{words[1], words[0]}, words[2:]...)
+	_, err = MnemonicToEntropy(strings.Join(swapped, " "))
+	assert.That(errors.Is(err, ErrMnemonic), "checksum")
+
+	typo := append([]string{"xxxxx"}, words[1:]...)
+	_, err = MnemonicToEntropy(strings.Join(typo, " "))
+	assert.That(errors.Is(err, ErrMnemonic), "unknown word")
+
+	// separators and case don't matter
+	loose := " " + strings.ToUpper(strings.Join(words, "-")) + "\n"
+	assert.DeepEqual(try.To1(MnemonicToEntropy(loose)), entropy)
+}
+
+func TestMnemonicToSeed(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	m := try.To1(NewMnemonic(EntropySize128))
+	seed := try.To1(MnemonicToSeed(m, ""))
+	assert.SLen(seed, MaxSeedLen)
+	assert.DeepEqual(try.To1(MnemonicToSeed(strings.ToUpper(m), "")), seed)
+
+	other := try.To1(MnemonicToSeed(m, "passphrase"))
+	assert.That(!crypto.EqualBytes(seed, other))
+
+	_, err := MnemonicToSeed("lusab babad", "")
+	assert.That(errors.Is(err, ErrMnemonic))
+}
@@ -0,0 +1,136 @@
+package hdkey
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+// Purpose is the first level of the wallet's paths, "IC" in ASCII.
+const Purpose uint32 = 0x4943
+
+// ScanLimit is how many key indexes Wallet searches for the chains' keys.
+const ScanLimit = 1024
+
+var ErrNotFound = errors.New("key isn't derived from the wallet")
+
+// Wallet derives the keys of a node from a single seed. The node's key
+// generations, i.e. the initial key and its rotations, are the paths
+// m/Purpose'/account'/index' where index is 0, 1, 2, etc. The pseudonym keys
+// of a generation are derived with chain.PseudonymKey, i.e. they are
+// deterministic as well.
+type Wallet struct {
+	account ExtendedKey
+}
+
+// NewWallet returns the wallet of the account from the mnemonic and the
+// optional passphrase, see MnemonicToSeed.
+func NewWallet(mnemonic, passphrase string, account uint32) (w Wallet, err error) {
+	defer err2.Handle(&err)
+
+	master := try.To1(NewMaster(try.To1(MnemonicToSeed(mnemonic, passphrase))))
+	return NewWalletFromMaster(master, account), nil
+}
+
+// NewWalletFromMaster returns the wallet of the account of the master key.
+func NewWalletFromMaster(master ExtendedKey, account uint32) Wallet {
+	return Wallet{account: master.Child(Purpose).Child(account)}
+}
+
+// Key returns the key of the generation index.
+func (w Wallet) Key(index uint32) crypto.Key {
+	return w.account.Child(index).Key()
+}
+
+// Index returns the generation index of the public key. It returns
+// ErrNotFound if the key isn't in the first ScanLimit keys of the wallet.
+func (w Wallet) Index(pubKey crypto.PubKey) (uint32, error) {
+	for i := uint32(0); i < ScanLimit; i++ {
+		if w.Key(i).PubKeyEqual(pubKey) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrNotFound, crypto.Fingerprint(pubKey))
+}
+
+// Keys regenerates the leaf keys of all of the node's chains, i.e. the
+// returned keys are in the same order as n.Chains. The leaf keys of the
+// pseudonym chains are the pseudonym keys, which are found only for the given
+// relationships, see PseudonymKey. It returns ErrNotFound for the keys which
+// aren't derived from the wallet.
+func (w Wallet) Keys(
+	n node.Node,
+	relationships ...[]byte,
+) ([]crypto.Key, error) {
+	keys := make([]crypto.Key, 0, n.Len())
+	found := make(map[string]crypto.Key)
+	for _, c := range n.Chains {
+		pubKey, err := c.TryLeafPubKey()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := found[string(pubKey)]
+		if !ok {
+			key, err = w.leafKey(c, relationships)
+			if err != nil {
+				return nil, err
+			}
+			found[string(pubKey)] = key
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// leafKey returns the leaf key of the chain c. The pseudonym key is searched
+// from the relationships of the member's key generation.
+func (w Wallet) leafKey(
+	c chain.Chain,
+	relationships [][]byte,
+) (crypto.Key, error) {
+	pubKey := c.LeafPubKey()
+	if !c.IsPseudonym() {
+		i, err := w.Index(pubKey)
+		if err != nil {
+			return crypto.Key{}, err
+		}
+		return w.Key(i), nil
+	}
+	// the member's key signed the pseudonym block
+	i, err := w.Index(c.Blocks[c.Len()-2].InviteePubKey)
+	if err != nil {
+		return crypto.Key{}, err
+	}
+	for _, r := range relationships {
+		if key := w.PseudonymKey(i, r); key.PubKeyEqual(pubKey) {
+			return key, nil
+		}
+	}
+	return crypto.Key{}, fmt.Errorf("%w: pseudonym %s of unknown relationship",
+		ErrNotFound, crypto.Fingerprint(pubKey))
+}
+
+// Rotate rotates the node's key to its next generation, see node.Node.Rotate.
+// All of the node's chains must have the same leaf key from the wallet. It
+// returns the rotated node and the new key.
+func (w Wallet) Rotate(n node.Node) (rn node.Node, key crypto.Key, err error) {
+	defer err2.Handle(&err)
+
+	if n.Len() == 0 {
+		return rn, key, chain.ErrEmptyChain
+	}
+	i := try.To1(w.Index(try.To1(n.Chains[0].TryLeafPubKey())))
+	key = w.Key(i + 1)
+	return n.Rotate(w.Key(i), key.PubKey), key, nil
+}
+
+// PseudonymKey returns the pseudonym key of the relationship for the
+// generation index, see chain.PseudonymKey.
+func (w Wallet) PseudonymKey(index uint32, relationship []byte) crypto.Key {
+	return chain.PseudonymKey(w.Key(index), relationship)
+}
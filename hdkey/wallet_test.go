@@ -0,0 +1,118 @@
+package hdkey
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+func TestWalletRecover(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	mnemonic := try.To1(NewMnemonic(EntropySize128))
+	w := try.To1(NewWallet(mnemonic, "", 0))
+	aliceKey := w.Key(0)
+
+	// alice joins two web-of-trusts
+	root1, root2 := crypto.NewKey(), crypto.NewKey()
+	alice := node.NewRootNode(root1.PubKey).
+		Invite(node.Node{}, root1, aliceKey.PubKey, 1)
+	alice = node.NewRootNode(root2.PubKey).
+		Invite(alice, root2, aliceKey.PubKey, 1)
+	assert.Equal(alice.Len(), 2)
+
+	// alice loses her device and restores the wallet from the mnemonic
+	restored := try.To1(NewWallet(mnemonic, "", 0))
+	keys := try.To1(restored.Keys(alice))
+	assert.SLen(keys, 2)
+	for i, k := range keys {
+		assert.DeepEqual(k, aliceKey)
+		assert.That(alice.Chains[i].Invite(k, crypto.NewKey().PubKey, 1).Verify())
+	}
+
+	// other passphrase or account is other wallet
+	other := try.To1(NewWallet(mnemonic, "other", 0))
+	_, err := other.Keys(alice)
+	assert.That(errors.Is(err, ErrNotFound))
+	other = try.To1(NewWallet(mnemonic, "", 1))
+	_, err = other.Keys(alice)
+	assert.That(errors.Is(err, ErrNotFound))
+
+	_, err = NewWallet("lusab babad", "", 0)
+	assert.That(errors.Is(err, ErrMnemonic))
+}
+
+func TestWalletRotate(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	w := try.To1(NewWallet(try.To1(NewMnemonic(EntropySize256)), "", 0))
+	rootKey := crypto.NewKey()
+	bob := node.NewRootNode(rootKey.PubKey).
+		Invite(node.Node{}, rootKey, w.Key(0).PubKey, 1)
+
+	bob, key, err := w.Rotate(bob)
+	try.To(err)
+	assert.DeepEqual(key, w.Key(1))
+	bob, key, err = w.Rotate(bob)
+	try.To(err)
+	assert.DeepEqual(key, w.Key(2))
+	assert.That(bob.Chains[0].Verify())
+	assert.Equal(bob.Chains[0].Depth(), 1)
+	assert.Equal(try.To1(w.Index(bob.Chains[0].LeafPubKey())), uint32(2))
+
+	// keys of all the generations are found
+	keys := try.To1(w.Keys(bob))
+	assert.DeepEqual(keys[0], w.Key(2))
+
+	_, _, err = w.Rotate(node.Node{})
+	assert.That(errors.Is(err, chain.ErrEmptyChain))
+	_, _, err = w.Rotate(node.NewRootNode(crypto.NewKey().PubKey))
+	assert.That(errors.Is(err, ErrNotFound))
+}
+
+func TestWalletPseudonym(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	mnemonic := try.To1(NewMnemonic(EntropySize128))
+	w := try.To1(NewWallet(mnemonic, "", 0))
+	rootKey := crypto.NewKey()
+	carol := chain.NewRootChain(rootKey.PubKey).
+		Invite(rootKey, w.Key(0).PubKey, 1)
+
+	verifier := crypto.NewKey().PubKey
+	pseudo := carol.Pseudonym(w.Key(0), w.PseudonymKey(0, verifier).PubKey)
+	assert.That(pseudo.Verify())
+
+	// restored wallet gives the same pseudonym for the verifier
+	restored := try.To1(NewWallet(mnemonic, "", 0))
+	assert.DeepEqual(restored.PseudonymKey(0, verifier).PubKey, pseudo.LeafPubKey())
+}
+
+func TestWalletKeysPseudonym(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	w := try.To1(NewWallet(try.To1(NewMnemonic(EntropySize128)), "", 0))
+	root1, root2 := crypto.NewKey(), crypto.NewKey()
+	dave := node.NewRootNode(root1.PubKey).
+		Invite(node.Node{}, root1, w.Key(0).PubKey, 1)
+	c := chain.NewRootChain(root2.PubKey).Invite(root2, w.Key(0).PubKey, 1)
+	verifier := []byte("verifier")
+	pseudoKey := w.PseudonymKey(0, verifier)
+	dave = dave.AddChain(c.Pseudonym(w.Key(0), pseudoKey.PubKey))
+
+	keys := try.To1(w.Keys(dave, []byte("other"), verifier))
+	assert.SLen(keys, 2)
+	assert.DeepEqual(keys[0], w.Key(0))
+	assert.DeepEqual(keys[1], pseudoKey)
+
+	// the pseudonym isn't found without its relationship
+	_, err := w.Keys(dave)
+	assert.That(errors.Is(err, ErrNotFound))
+	_, err = w.Keys(dave, []byte("other"))
+	assert.That(errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,365 @@
+// Package httpapi implements a plain HTTP API of the Invitation Chain for web
+// frontends. It's built on net/http. Request and response bodies are JSON, but
+// the chains can also be sent and received in the canonical binary encoding,
+// see chain.EncodingVersion, by using ContentTypeBinary in Content-Type and
+// Accept headers.
+//
+// Endpoints, all are POST:
+//
+//	/v1/verify             chain -> verification report, ?at=<Unix seconds>
+//	                       checks the validity windows at the time
+//	/v1/hops               two nodes -> web-of-trust information
+//	/v1/invite             chain, invitee's key and position -> chain
+//	/v1/challenges         chain -> challenge
+//	/v1/challenges/verify  chain and challenge response -> verification
+//
+// Errors are returned as structured JSON bodies, see Error.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeBinary = "application/octet-stream"
+
+	// MaxBodySize is the maximum size of the request body.
+	MaxBodySize = 1 << 20
+)
+
+// Error is the error body of the failed requests.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorBody struct {
+	Error Error `json:"error"`
+}
+
+// VerifyResult is the response of /v1/verify.
+type VerifyResult struct {
+	OK      bool          `json:"ok"`
+	Block   int           `json:"block"`
+	Failure chain.Failure `json:"failure"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HopsRequest is the request of /v1/hops.
+type HopsRequest struct {
+	Node1 node.Node `json:"node1"`
+	Node2 node.Node `json:"node2"`
+}
+
+// HopsResult is the response of /v1/hops. If the nodes aren't connected Hops is
+// chain.NotConnected.
+type HopsResult struct {
+	Hops          int            `json:"hops"`
+	CommonInviter int            `json:"common_inviter"`
+	Position      chain.Position `json:"position"`
+}
+
+// InviteRequest is the request of /v1/invite. The chain's leaf key must be the
+// server key.
+type InviteRequest struct {
+	Chain         chain.Chain    `json:"chain"`
+	InviteePubKey chain.Base64   `json:"invitee_pub_key"`
+	Position      chain.Position `json:"position"`
+}
+
+// ChallengeVerifyRequest is the request of /v1/challenges/verify.
+type ChallengeVerifyRequest struct {
+	Chain    chain.Chain        `json:"chain"`
+	Response challenge.Response `json:"response"`
+}
+
+// Server is the http.Handler of the API. It invites with the server-held key,
+// which is also the audience of the challenges. It's safe for concurrent use.
+type Server struct {
+	key      crypto.KeyHandle
+	verifier *challenge.Verifier
+	mux      *http.ServeMux
+}
+
+// NewServer returns a new server which uses the key for invitations and
+// challenges.
+func NewServer(key crypto.KeyHandle) *Server {
+	s := &Server{
+		key:      key,
+		verifier: challenge.NewVerifier(key.PublicKey(), 0),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/v1/verify", post(s.verify))
+	s.mux.HandleFunc("/v1/hops", post(s.hops))
+	s.mux.HandleFunc("/v1/invite", post(s.invite))
+	s.mux.HandleFunc("/v1/challenges", post(s.newChallenge))
+	s.mux.HandleFunc("/v1/challenges/verify", post(s.verifyChallenge))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) error {
+	c, err := readChain(r)
+	if err != nil {
+		return err
+	}
+	rep := c.VerifyReport()
+	if at := r.URL.Query().Get("at"); at != "" {
+		sec, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: at: %v", chain.ErrDecode, err)
+		}
+		if sec != 0 {
+			rep = c.VerifyReportAt(time.Unix(sec, 0))
+		}
+	}
+	res := VerifyResult{OK: rep.OK(), Block: rep.Block, Failure: rep.Failure}
+	if !rep.OK() {
+		res.Error = rep.Err().Error()
+	}
+	return writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) hops(w http.ResponseWriter, r *http.Request) error {
+	var req HopsRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	wot := node.NewWebOfTrust(req.Node1, req.Node2)
+	return writeJSON(w, http.StatusOK, HopsResult{
+		Hops:          wot.Hops,
+		CommonInviter: wot.CommonInvider,
+		Position:      wot.Position,
+	})
+}
+
+func (s *Server) invite(w http.ResponseWriter, r *http.Request) error {
+	var req InviteRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if err := req.Chain.TryVerify(); err != nil {
+		return err
+	}
+	nc, err := req.Chain.TryInvite(s.key, req.InviteePubKey, req.Position)
+	if err != nil {
+		return err
+	}
+	return writeChain(w, r, http.StatusCreated, nc)
+}
+
+func (s *Server) newChallenge(w http.ResponseWriter, r *http.Request) error {
+	c, err := readChain(r)
+	if err != nil {
+		return err
+	}
+	ch, err := s.verifier.New(c)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, ch)
+}
+
+func (s *Server) verifyChallenge(w http.ResponseWriter, r *http.Request) error {
+	var req ChallengeVerifyRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if err := s.verifier.Verify(req.Chain, req.Response); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// errMethod and errContentType are the request errors of this package.
+var (
+	errMethod      = errors.New("method not allowed")
+	errContentType = errors.New("unsupported content type")
+)
+
+// errStatus maps the errors to HTTP status codes and error codes. The first
+// match wins.
+var errStatus = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errMethod, http.StatusMethodNotAllowed, "method_not_allowed"},
+	{errContentType, http.StatusUnsupportedMediaType, "unsupported_content_type"},
+	{chain.ErrDecode, http.StatusBadRequest, "decode"},
+	{chain.ErrEmptyChain, http.StatusBadRequest, "empty_chain"},
+	{challenge.ErrMalformed, http.StatusBadRequest, "malformed_challenge"},
+	{chain.ErrNotLeaf, http.StatusForbidden, "not_leaf"},
+	{chain.ErrPosition, http.StatusForbidden, "position"},
+	{chain.ErrBadRoot, http.StatusUnprocessableEntity, "bad_root"},
+	{chain.ErrHashLink, http.StatusUnprocessableEntity, "hash_link"},
+	{chain.ErrBadSignature, http.StatusUnprocessableEntity, "bad_signature"},
+	{chain.ErrRotation, http.StatusUnprocessableEntity, "rotation"},
+	{chain.ErrAcceptance, http.StatusUnprocessableEntity, "acceptance"},
+	{chain.ErrAlgorithm, http.StatusUnprocessableEntity, "algorithm"},
+	{chain.ErrExpired, http.StatusUnprocessableEntity, "expired"},
+	{chain.ErrRevoked, http.StatusUnprocessableEntity, "revoked"},
+	{challenge.ErrExpired, http.StatusUnauthorized, "challenge_expired"},
+	{challenge.ErrUnknownNonce, http.StatusUnauthorized, "unknown_nonce"},
+	{challenge.ErrReplay, http.StatusUnauthorized, "replay"},
+	{challenge.ErrAudience, http.StatusUnauthorized, "audience"},
+	{challenge.ErrSubject, http.StatusUnauthorized, "subject"},
+	{challenge.ErrBadSignature, http.StatusUnauthorized, "bad_signature"},
+	{challenge.ErrChain, http.StatusUnauthorized, "chain"},
+}
+
+// post returns a handler which accepts only POST requests and writes the
+// errors of the h as Error bodies.
+func post(h func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			err = errMethod
+		} else {
+			r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+			err = h(w, r)
+		}
+		if err != nil {
+			writeError(w, err)
+		}
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, code := http.StatusInternalServerError, "internal"
+	for _, es := range errStatus {
+		if errors.Is(err, es.err) {
+			status, code = es.status, es.code
+			break
+		}
+	}
+	_ = writeJSON(w, status, errorBody{Error{Code: code, Message: err.Error()}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(d)
+	return err
+}
+
+// writeChain writes the chain in the binary encoding if the client accepts it
+// but not JSON, and as JSON otherwise.
+func writeChain(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	c chain.Chain,
+) error {
+	if !accepts(r, ContentTypeBinary) {
+		return writeJSON(w, status, c)
+	}
+	d, err := c.TryBytes()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeBinary)
+	w.WriteHeader(status)
+	_, err = w.Write(d)
+	return err
+}
+
+// accepts tells if the Accept header of the request prefers the content type
+// over JSON. JSON is the default.
+func accepts(r *http.Request, contentType string) bool {
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case contentType:
+			return true
+		case ContentTypeJSON, "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// readChain reads the chain from the request body. The Content-Type tells its
+// encoding.
+func readChain(r *http.Request) (c chain.Chain, err error) {
+	mt, err := mediaType(r)
+	if err != nil {
+		return c, err
+	}
+	if mt == ContentTypeJSON {
+		if err := readJSON(r, &c); err != nil {
+			return c, err
+		}
+		if c.Len() == 0 {
+			return c, chain.ErrEmptyChain
+		}
+		return c, nil
+	}
+	d, err := io.ReadAll(r.Body)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", chain.ErrDecode, err)
+	}
+	return chain.ParseChain(d)
+}
+
+func readJSON(r *http.Request, v any) error {
+	mt, err := mediaType(r)
+	if err != nil {
+		return err
+	}
+	if mt != ContentTypeJSON {
+		return fmt.Errorf("%w: %s", errContentType, mt)
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, chain.ErrDecode) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", chain.ErrDecode, err)
+	}
+	return nil
+}
+
+// mediaType returns the media type of the request body. JSON is the default.
+func mediaType(r *http.Request) (string, error) {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return ContentTypeJSON, nil
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errContentType, err)
+	}
+	switch mt {
+	case ContentTypeJSON, ContentTypeBinary:
+		return mt, nil
+	}
+	return "", fmt.Errorf("%w: %s", errContentType, mt)
+}
@@ -0,0 +1,246 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+var (
+	serverKey, aliceKey crypto.Key
+	root, alice         chain.Chain
+	ts                  *httptest.Server
+)
+
+func TestMain(m *testing.M) {
+	serverKey = crypto.NewKey()
+	aliceKey = crypto.NewKey()
+	root = chain.NewRootChain(serverKey.PubKey)
+	alice = root.Invite(serverKey, aliceKey.PubKey, 1)
+
+	ts = httptest.NewServer(NewServer(serverKey.Handle()))
+	code := m.Run()
+	ts.Close()
+	os.Exit(code)
+}
+
+func doPost(path, contentType, accept string, body []byte) *http.Response {
+	req := try.To1(http.NewRequest(http.MethodPost, ts.URL+path,
+		bytes.NewReader(body)))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	return try.To1(http.DefaultClient.Do(req))
+}
+
+func postJSON(path string, v any) *http.Response {
+	return doPost(path, ContentTypeJSON, "", try.To1(json.Marshal(v)))
+}
+
+func decode[T any](resp *http.Response) (v T) {
+	defer resp.Body.Close()
+	try.To(json.NewDecoder(resp.Body).Decode(&v))
+	return v
+}
+
+func errorCode(resp *http.Response) string {
+	return decode[errorBody](resp).Error.Code
+}
+
+func TestVerify(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	resp := postJSON("/v1/verify", alice)
+	assert.Equal(resp.StatusCode, http.StatusOK)
+	assert.That(decode[VerifyResult](resp).OK)
+
+	resp = doPost("/v1/verify", ContentTypeBinary, "", alice.Bytes())
+	assert.Equal(resp.StatusCode, http.StatusOK)
+	assert.That(decode[VerifyResult](resp).OK)
+
+	broken := alice.Clone()
+	broken.Blocks[1].InvitersSignature[0] ^= 0x01
+	resp = doPost("/v1/verify", ContentTypeBinary, "", broken.Bytes())
+	res := decode[VerifyResult](resp)
+	assert.ThatNot(res.OK)
+	assert.Equal(res.Block, 1)
+	assert.Equal(res.Failure, chain.FailSignature)
+	assert.NotEmpty(res.Error)
+}
+
+func TestVerifyAt(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	c := root.InviteValid(serverKey, crypto.NewKey().PubKey, 1,
+		chain.ValidFor(time.Hour))
+	expired := time.Now().Add(2 * time.Hour).Unix()
+
+	resp := doPost("/v1/verify", ContentTypeBinary, "", c.Bytes())
+	assert.That(decode[VerifyResult](resp).OK, "no time given")
+	resp = doPost("/v1/verify?at=0", ContentTypeBinary, "", c.Bytes())
+	assert.That(decode[VerifyResult](resp).OK)
+	resp = doPost(fmt.Sprintf("/v1/verify?at=%d", time.Now().Unix()),
+		ContentTypeBinary, "", c.Bytes())
+	assert.That(decode[VerifyResult](resp).OK)
+
+	resp = doPost(fmt.Sprintf("/v1/verify?at=%d", expired),
+		ContentTypeBinary, "", c.Bytes())
+	res := decode[VerifyResult](resp)
+	assert.ThatNot(res.OK)
+	assert.Equal(res.Failure, chain.FailExpired)
+
+	resp = doPost("/v1/verify?at=yesterday", ContentTypeBinary, "", c.Bytes())
+	assert.Equal(resp.StatusCode, http.StatusBadRequest)
+	assert.Equal(errorCode(resp), "decode")
+}
+
+func TestBase64URL(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// keys are in the same format as the command-line tool prints them
+	key := crypto.NewKey()
+	pubKey := base64.RawURLEncoding.EncodeToString(key.PubKey)
+	body := fmt.Sprintf(`{"chain":%s,"invitee_pub_key":%q,"position":1}`,
+		try.To1(json.Marshal(root)), pubKey)
+	resp := doPost("/v1/invite", ContentTypeJSON, "", []byte(body))
+	assert.Equal(resp.StatusCode, http.StatusCreated)
+	assert.DeepEqual(decode[chain.Chain](resp).LeafPubKey(), key.PubKey)
+
+	resp = postJSON("/v1/challenges", alice)
+	ch := decode[map[string]any](resp)
+	assert.DeepEqual(ch["subject"],
+		base64.RawURLEncoding.EncodeToString(alice.LeafPubKey()))
+}
+
+func TestErrors(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	resp := try.To1(http.Get(ts.URL + "/v1/verify"))
+	assert.Equal(resp.StatusCode, http.StatusMethodNotAllowed)
+	assert.Equal(resp.Header.Get("Content-Type"), ContentTypeJSON)
+	assert.Equal(errorCode(resp), "method_not_allowed")
+
+	resp = doPost("/v1/verify", "text/plain", "", []byte("x"))
+	assert.Equal(resp.StatusCode, http.StatusUnsupportedMediaType)
+	assert.Equal(errorCode(resp), "unsupported_content_type")
+
+	resp = doPost("/v1/verify", ContentTypeBinary, "", []byte{1, 2, 3})
+	assert.Equal(resp.StatusCode, http.StatusBadRequest)
+	assert.Equal(errorCode(resp), "decode")
+
+	resp = doPost("/v1/verify", ContentTypeJSON, "", []byte(`{"blocks":[]}`))
+	assert.Equal(resp.StatusCode, http.StatusBadRequest)
+	assert.Equal(errorCode(resp), "empty_chain")
+
+	resp = doPost("/v1/hops", ContentTypeJSON, "", []byte(`{"nodes":1}`))
+	assert.Equal(resp.StatusCode, http.StatusBadRequest)
+	assert.Equal(errorCode(resp), "decode")
+
+	resp = doPost("/v1/verify", ContentTypeBinary, "",
+		make([]byte, MaxBodySize+1))
+	assert.Equal(resp.StatusCode, http.StatusBadRequest)
+}
+
+func TestHops(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	bobKey := crypto.NewKey()
+	bob := root.Invite(serverKey, bobKey.PubKey, 1)
+	resp := postJSON("/v1/hops", HopsRequest{
+		Node1: node.Node{Chains: []chain.Chain{alice}},
+		Node2: node.Node{Chains: []chain.Chain{bob}},
+	})
+	assert.Equal(resp.StatusCode, http.StatusOK)
+	res := decode[HopsResult](resp)
+	assert.Equal(res.Hops, 2)
+	assert.Equal(res.CommonInviter, 0)
+	assert.Equal(res.Position, chain.RootPosition)
+
+	resp = postJSON("/v1/hops", HopsRequest{
+		Node1: node.Node{Chains: []chain.Chain{alice}},
+		Node2: node.NewRootNode(crypto.NewKey().PubKey),
+	})
+	assert.Equal(decode[HopsResult](resp).Hops, chain.NotConnected)
+}
+
+func TestInvite(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	bobKey := crypto.NewKey()
+	req := InviteRequest{Chain: root, InviteePubKey: bobKey.PubKey, Position: 1}
+
+	resp := postJSON("/v1/invite", req)
+	assert.Equal(resp.StatusCode, http.StatusCreated)
+	bob := decode[chain.Chain](resp)
+	assert.That(bob.Verify())
+	assert.DeepEqual(bob.LeafPubKey(), bobKey.PubKey)
+
+	// binary response
+	resp = doPost("/v1/invite", ContentTypeJSON, ContentTypeBinary,
+		try.To1(json.Marshal(req)))
+	assert.Equal(resp.StatusCode, http.StatusCreated)
+	assert.Equal(resp.Header.Get("Content-Type"), ContentTypeBinary)
+	d := try.To1(io.ReadAll(resp.Body))
+	resp.Body.Close()
+	assert.That(try.To1(chain.ParseChain(d)).Verify())
+
+	// the server can invite only with its own key
+	resp = postJSON("/v1/invite", InviteRequest{Chain: alice,
+		InviteePubKey: bobKey.PubKey, Position: 1})
+	assert.Equal(resp.StatusCode, http.StatusForbidden)
+	assert.Equal(errorCode(resp), "not_leaf")
+
+	resp = postJSON("/v1/invite", InviteRequest{Chain: root,
+		InviteePubKey: bobKey.PubKey, Position: -1})
+	assert.Equal(resp.StatusCode, http.StatusForbidden)
+	assert.Equal(errorCode(resp), "position")
+
+	// malformed keys are client errors
+	resp = postJSON("/v1/invite", InviteRequest{Chain: root,
+		InviteePubKey: bobKey.PubKey[:5], Position: 1})
+	assert.Equal(resp.StatusCode, http.StatusUnprocessableEntity)
+	assert.Equal(errorCode(resp), "algorithm")
+	broken := root.Clone()
+	broken.Blocks[0].Algorithm = crypto.P256
+	resp = postJSON("/v1/invite", InviteRequest{Chain: broken,
+		InviteePubKey: bobKey.PubKey, Position: 1})
+	assert.Equal(resp.StatusCode, http.StatusUnprocessableEntity)
+	assert.Equal(errorCode(resp), "algorithm")
+}
+
+func TestChallenge(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	resp := doPost("/v1/challenges", ContentTypeBinary, "", alice.Bytes())
+	assert.Equal(resp.StatusCode, http.StatusCreated)
+	ch := decode[challenge.Challenge](resp)
+
+	r := try.To1(challenge.NewResponder(aliceKey.Handle(), alice))
+	rr := try.To1(r.Respond(ch, serverKey.PubKey))
+	req := ChallengeVerifyRequest{Chain: alice, Response: rr}
+	resp = postJSON("/v1/challenges/verify", req)
+	assert.Equal(resp.StatusCode, http.StatusNoContent)
+	resp.Body.Close()
+
+	// replay
+	resp = postJSON("/v1/challenges/verify", req)
+	assert.Equal(resp.StatusCode, http.StatusUnauthorized)
+	assert.Equal(errorCode(resp), "replay")
+}
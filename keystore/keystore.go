@@ -0,0 +1,187 @@
+// Package keystore implements encrypted, file-backed storage for the keys used
+// to sign invitation chain blocks, see crypto.Algorithm. Keys are encrypted
+// with AES-GCM and the encryption key is derived from a passphrase with
+// scrypt. The loaded keys are given out as crypto.KeyHandle, i.e. private keys
+// aren't exposed.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	fileVersion = 1
+	kdfScrypt   = "scrypt"
+	saltLen     = 32
+	aesKeyLen   = 32
+)
+
+var (
+	// ErrDecrypt tells that the passphrase is wrong or the file is tampered.
+	// AEAD cannot tell which one.
+	ErrDecrypt = errors.New("wrong passphrase or tampered key file")
+
+	// ErrFormat tells that the key file isn't in the supported format.
+	ErrFormat = errors.New("unsupported key file format")
+)
+
+// scrypt cost parameters for new key files. They are stored to the files, so
+// they can be changed without breaking the old files.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Upper limits of the scrypt parameters we accept from the key files. Without
+// them a tampered file could make us allocate all the memory.
+const (
+	maxScryptN = 1 << 20
+	maxScryptR = 32
+	maxScryptP = 16
+)
+
+type kdfParams struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// header is the authenticated but not encrypted part of the key file.
+type header struct {
+	Version int           `json:"version"`
+	KDF     kdfParams     `json:"kdf"`
+	PubKey  crypto.PubKey `json:"pub_key"`
+}
+
+type keyFile struct {
+	header
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Save encrypts the key with the passphrase and writes it to the path. Existing
+// files aren't overwritten.
+func Save(path string, key crypto.Key, passphrase []byte) (err error) {
+	defer err2.Handle(&err)
+
+	return writeFile(path, try.To1(Encrypt(key, passphrase)))
+}
+
+// writeFile writes the key file content d to the new file of the path. The
+// file is removed if the write fails, i.e. it can be retried.
+func writeFile(path string, d []byte) (err error) {
+	defer err2.Handle(&err)
+
+	f := try.To1(os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600))
+	defer err2.Handle(&err, func(err error) error {
+		_ = os.Remove(path)
+		return err
+	})
+	_, err = f.Write(d)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Open reads the key file from the path and decrypts it with the passphrase.
+func Open(path string, passphrase []byte) (h crypto.KeyHandle, err error) {
+	defer err2.Handle(&err)
+
+	key := try.To1(Decrypt(try.To1(os.ReadFile(path)), passphrase))
+	return key.Handle(), nil
+}
+
+// Encrypt returns the encrypted key file content of the key.
+func Encrypt(key crypto.Key, passphrase []byte) (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	kf := keyFile{header: header{
+		Version: fileVersion,
+		KDF: kdfParams{
+			Name: kdfScrypt,
+			N:    scryptN,
+			R:    scryptR,
+			P:    scryptP,
+			Salt: crypto.RandSlice(saltLen),
+		},
+		PubKey: key.PubKey,
+	}}
+	aead := try.To1(kf.aead(passphrase))
+	kf.Nonce = crypto.RandSlice(aead.NonceSize())
+	kf.Ciphertext = aead.Seal(nil, kf.Nonce, key.Secret(), try.To1(kf.ad()))
+	return json.Marshal(kf)
+}
+
+// Decrypt returns the key of the encrypted key file content d. It returns
+// ErrDecrypt if the passphrase is wrong or d is tampered.
+func Decrypt(d []byte, passphrase []byte) (key crypto.Key, err error) {
+	defer err2.Handle(&err)
+
+	var kf keyFile
+	if err := json.Unmarshal(d, &kf); err != nil {
+		return key, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if kf.Version != fileVersion || kf.KDF.Name != kdfScrypt {
+		return key, ErrFormat
+	}
+	aead := try.To1(kf.aead(passphrase))
+	if len(kf.Nonce) != aead.NonceSize() {
+		return key, ErrFormat
+	}
+	alg, _, err := crypto.ParsePubKey(kf.PubKey)
+	if err != nil {
+		return key, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	secret, err := aead.Open(nil, kf.Nonce, kf.Ciphertext, try.To1(kf.ad()))
+	if err != nil {
+		return key, ErrDecrypt
+	}
+	key, err = crypto.NewKeyFromSecret(alg, secret)
+	if err != nil || !key.PubKeyEqual(kf.PubKey) {
+		return crypto.Key{}, ErrDecrypt
+	}
+	return key, nil
+}
+
+// PubKey returns the public key of the encrypted key file content d without
+// decrypting it.
+func PubKey(d []byte) (pubKey crypto.PubKey, err error) {
+	var kf keyFile
+	if err := json.Unmarshal(d, &kf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return kf.PubKey, nil
+}
+
+func (kf keyFile) aead(passphrase []byte) (aead cipher.AEAD, err error) {
+	defer err2.Handle(&err)
+
+	k := kf.KDF
+	if k.N > maxScryptN || k.R > maxScryptR || k.P > maxScryptP {
+		return nil, fmt.Errorf("%w: too expensive scrypt parameters", ErrFormat)
+	}
+	key, err := scrypt.Key(passphrase, k.Salt, k.N, k.R, k.P, aesKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return cipher.NewGCM(try.To1(aes.NewCipher(key)))
+}
+
+// ad returns the additional data of the AEAD, i.e. the header is authenticated.
+func (kf keyFile) ad() ([]byte, error) {
+	return json.Marshal(kf.header)
+}
@@ -0,0 +1,113 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+var passphrase = []byte("correct horse battery staple")
+
+func TestMain(m *testing.M) {
+	// keep the tests fast, real files use the default cost
+	scryptN = 1 << 10
+	os.Exit(m.Run())
+}
+
+func TestSaveOpen(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	key := crypto.NewKey()
+	try.To(Save(path, key, passphrase))
+	assert.Error(Save(path, key, passphrase), "no overwrites")
+
+	h := try.To1(Open(path, passphrase))
+	assert.DeepEqual(h.PublicKey(), key.PubKey)
+	msg := []byte("message")
+	assert.That(key.VerifySign(msg, h.Sign(msg)))
+
+	_, err := Open(path, []byte("wrong"))
+	assert.That(errors.Is(err, ErrDecrypt))
+}
+
+func TestKeyHandleInvite(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "root.json")
+	rootKey := crypto.NewKey()
+	try.To(Save(path, rootKey, passphrase))
+	h := try.To1(Open(path, passphrase))
+
+	c := chain.NewRootChain(h.PublicKey())
+	c = c.Invite(h, crypto.NewKey().PubKey, 1)
+	assert.That(c.Verify())
+}
+
+func TestTampered(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	key := crypto.NewKey()
+	d := try.To1(Encrypt(key, passphrase))
+
+	tamper := func(f func(kf *keyFile)) []byte {
+		var kf keyFile
+		try.To(json.Unmarshal(d, &kf))
+		f(&kf)
+		return try.To1(json.Marshal(kf))
+	}
+	tests := []struct {
+		name string
+		d    []byte
+		want error
+	}{
+		{"pub key", tamper(func(kf *keyFile) {
+			kf.PubKey = crypto.NewKey().PubKey
+		}), ErrDecrypt},
+		{"ciphertext", tamper(func(kf *keyFile) {
+			kf.Ciphertext[0] ^= 0x01
+		}), ErrDecrypt},
+		{"salt", tamper(func(kf *keyFile) {
+			kf.KDF.Salt[0] ^= 0x01
+		}), ErrDecrypt},
+		{"cost", tamper(func(kf *keyFile) {
+			kf.KDF.N = 1 << 30
+		}), ErrFormat},
+		{"version", tamper(func(kf *keyFile) {
+			kf.Version = 2
+		}), ErrFormat},
+		{"nonce", tamper(func(kf *keyFile) {
+			kf.Nonce = kf.Nonce[1:]
+		}), ErrFormat},
+		{"not json", []byte("key"), ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer assert.PushTester(t)()
+
+			_, err := Decrypt(tt.d, passphrase)
+			assert.That(errors.Is(err, tt.want), err)
+		})
+	}
+}
+
+func TestSaveOpenP256(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	key := try.To1(crypto.GenerateKey(crypto.P256))
+	try.To(Save(path, key, passphrase))
+
+	h := try.To1(Open(path, passphrase))
+	assert.DeepEqual(h.PublicKey(), key.PubKey)
+	c := chain.NewRootChain(h.PublicKey())
+	c = c.Invite(h, crypto.NewKey().PubKey, 1)
+	assert.That(c.Verify())
+}
@@ -0,0 +1,166 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+const fileExt = ".key"
+
+var (
+	ErrNotFound = errors.New("key not found")
+	ErrExists   = errors.New("key already exists")
+	ErrID       = errors.New("invalid key ID")
+)
+
+// Store is a directory of encrypted key files. Every file is named by the key
+// ID, which is the crypto.Fingerprint of the public key.
+type Store struct {
+	dir string
+}
+
+// Entry describes the stored key without decrypting it.
+type Entry struct {
+	ID     string
+	PubKey crypto.PubKey
+
+	// Err tells why the key file couldn't be read. PubKey is nil then.
+	Err error
+}
+
+// ID returns the key ID of the pubKey.
+func ID(pubKey crypto.PubKey) string {
+	return crypto.Fingerprint(pubKey)
+}
+
+// NewStore returns the Store of the dir. The dir is created if it doesn't
+// exist.
+func NewStore(dir string) (s Store, err error) {
+	defer err2.Handle(&err)
+
+	try.To(os.MkdirAll(dir, 0o700))
+	return Store{dir: dir}, nil
+}
+
+// Generate creates a new key, stores it and returns its ID.
+func (s Store) Generate(passphrase []byte) (id string, err error) {
+	return s.Add(crypto.NewKey(), passphrase)
+}
+
+// Add encrypts the key with the passphrase, stores it and returns its ID.
+func (s Store) Add(key crypto.Key, passphrase []byte) (id string, err error) {
+	defer err2.Handle(&err)
+
+	id = ID(key.PubKey)
+	err = Save(s.path(id), key, passphrase)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%w: %s", ErrExists, id)
+	}
+	return id, err
+}
+
+// Open returns the key handle of the id. The key file must be decryptable with
+// the passphrase and its public key must match to the id.
+func (s Store) Open(id string, passphrase []byte) (h crypto.KeyHandle, err error) {
+	defer err2.Handle(&err)
+
+	key := try.To1(Decrypt(try.To1(s.read(id)), passphrase))
+	if ID(key.PubKey) != id {
+		return nil, ErrDecrypt
+	}
+	return key.Handle(), nil
+}
+
+// List returns the entries of all the stored keys sorted by their IDs. The
+// unreadable key files don't fail the listing, see Entry.Err.
+func (s Store) List() (entries []Entry, err error) {
+	defer err2.Handle(&err)
+
+	files := try.To1(os.ReadDir(s.dir))
+	entries = make([]Entry, 0, len(files))
+	for _, f := range files {
+		id := strings.TrimSuffix(f.Name(), fileExt)
+		if f.IsDir() || id == f.Name() || validID(id) != nil {
+			continue
+		}
+		e := Entry{ID: id}
+		d, err := s.read(id)
+		if err == nil {
+			e.PubKey, err = PubKey(d)
+		}
+		e.Err = err
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+// Export returns the encrypted key file content of the id. It can be imported
+// to other Store with the same passphrase.
+func (s Store) Export(id string) (d []byte, err error) {
+	defer err2.Handle(&err)
+
+	return s.read(id)
+}
+
+// Import stores the encrypted key file content d exported from other Store. The
+// passphrase is needed to check that d isn't tampered.
+func (s Store) Import(d []byte, passphrase []byte) (id string, err error) {
+	defer err2.Handle(&err)
+
+	key := try.To1(Decrypt(d, passphrase))
+	id = ID(key.PubKey)
+	err = writeFile(s.path(id), d)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%w: %s", ErrExists, id)
+	}
+	return id, err
+}
+
+// Delete removes the key of the id.
+func (s Store) Delete(id string) (err error) {
+	defer err2.Handle(&err)
+
+	try.To(validID(id))
+	err = os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return err
+}
+
+func (s Store) read(id string) (d []byte, err error) {
+	if err := validID(id); err != nil {
+		return nil, err
+	}
+	d, err = os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return d, err
+}
+
+func (s Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileExt)
+}
+
+// validID checks that the id is a hex encoded fingerprint, i.e. it cannot
+// point outside of the store directory.
+func validID(id string) error {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) == 0 || hex.EncodeToString(b) != id {
+		return fmt.Errorf("%w: %q", ErrID, id)
+	}
+	return nil
+}
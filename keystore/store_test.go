@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/crypto"
+)
+
+func TestStore(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s := try.To1(NewStore(filepath.Join(t.TempDir(), "keys")))
+	assert.SLen(try.To1(s.List()), 0)
+
+	key := crypto.NewKey()
+	id := try.To1(s.Add(key, passphrase))
+	assert.Equal(id, crypto.Fingerprint(key.PubKey))
+	_, err := s.Add(key, passphrase)
+	assert.That(errors.Is(err, ErrExists))
+
+	id2 := try.To1(s.Generate(passphrase))
+	entries := try.To1(s.List())
+	assert.SLen(entries, 2)
+	for _, e := range entries {
+		assert.That(e.ID == id || e.ID == id2)
+		assert.Equal(e.ID, ID(e.PubKey))
+	}
+
+	h := try.To1(s.Open(id, passphrase))
+	assert.DeepEqual(h.PublicKey(), key.PubKey)
+	_, err = s.Open(id, []byte("wrong"))
+	assert.That(errors.Is(err, ErrDecrypt))
+
+	try.To(s.Delete(id))
+	_, err = s.Open(id, passphrase)
+	assert.That(errors.Is(err, ErrNotFound))
+	assert.That(errors.Is(s.Delete(id), ErrNotFound))
+	assert.SLen(try.To1(s.List()), 1)
+}
+
+func TestStoreExportImport(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s1 := try.To1(NewStore(t.TempDir()))
+	s2 := try.To1(NewStore(t.TempDir()))
+
+	id := try.To1(s1.Generate(passphrase))
+	d := try.To1(s1.Export(id))
+
+	_, err := s2.Import(d, []byte("wrong"))
+	assert.That(errors.Is(err, ErrDecrypt))
+	d[len(d)/2] ^= 0x01
+	_, err = s2.Import(d, passphrase)
+	assert.Error(err, "tampered export")
+	d[len(d)/2] ^= 0x01
+
+	assert.Equal(try.To1(s2.Import(d, passphrase)), id)
+	_, err = s2.Import(d, passphrase)
+	assert.That(errors.Is(err, ErrExists))
+	try.To1(s2.Open(id, passphrase))
+}
+
+func TestStoreRenamedFile(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s := try.To1(NewStore(t.TempDir()))
+	id := try.To1(s.Generate(passphrase))
+	id2 := try.To1(s.Generate(passphrase))
+
+	// replace id2's file with id's file, i.e. the content doesn't match to ID
+	try.To(os.Rename(s.path(id), s.path(id2)))
+	_, err := s.Open(id2, passphrase)
+	assert.That(errors.Is(err, ErrDecrypt))
+}
+
+func TestStoreInvalidID(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s := try.To1(NewStore(t.TempDir()))
+	for _, id := range []string{"", "../key", "ABCD", "abc"} {
+		_, err := s.Open(id, passphrase)
+		assert.That(errors.Is(err, ErrID), id)
+		assert.That(errors.Is(s.Delete(id), ErrID), id)
+	}
+}
+
+func TestStoreListUnreadable(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	s := try.To1(NewStore(t.TempDir()))
+	id := try.To1(s.Generate(passphrase))
+	try.To(os.WriteFile(s.path("00"), []byte("garbage"), 0o600))
+
+	entries := try.To1(s.List())
+	assert.SLen(entries, 2)
+	assert.Equal(entries[0].ID, "00")
+	assert.That(errors.Is(entries[0].Err, ErrFormat))
+	assert.SLen(entries[0].PubKey, 0)
+	assert.Equal(entries[1].ID, id)
+	assert.NoError(entries[1].Err)
+}
@@ -1,12 +1,14 @@
 package node
 
 import (
+	"strings"
+
 	"github.com/lainio/ic/chain"
 	"github.com/lainio/ic/crypto"
 )
 
 type Node struct {
-	Chains []chain.Chain
+	Chains []chain.Chain `json:"chains"`
 }
 
 type WebOfTrust struct {
@@ -18,7 +20,7 @@ type WebOfTrust struct {
 	CommonInvider int
 
 	// Position of the CommonInvider.
-	Position int
+	Position chain.Position
 }
 
 // NewWebOfTrust returns web-of-trust information of two nodes if they share a
@@ -41,9 +43,9 @@ func (n Node) AddChain(c chain.Chain) (rn Node) {
 
 func (n Node) Invite(
 	inviteesNode Node,
-	invitersKey crypto.Key, // TODO: KeyHandle to hide KMS
+	invitersKey crypto.KeyHandle,
 	inviteesPubKey crypto.PubKey,
-	position int,
+	position chain.Position,
 ) (
 	rn Node,
 ) {
@@ -67,6 +69,16 @@ func (n Node) Invite(
 	return rn
 }
 
+// Rotate changes the node's key in all of its chains. The node keeps its
+// position in every web-of-trust, see chain.Chain.Rotate.
+func (n Node) Rotate(currentKey crypto.KeyHandle, newPubKey crypto.PubKey) (rn Node) {
+	rn.Chains = make([]chain.Chain, 0, n.Len())
+	for _, c := range n.Chains {
+		rn.Chains = append(rn.Chains, c.Rotate(currentKey, newPubKey))
+	}
+	return rn
+}
+
 // CommonChains return slice of chain pairs. If no pairs can be found the slice
 // is empty not nil.
 func (n Node) CommonChains(their Node) []chain.Pair {
@@ -88,6 +100,7 @@ func (n Node) WebOfTrustInfo(their Node) WebOfTrust {
 
 	hops := chain.NotConnected
 	fromRoot := chain.NotConnected
+	position := chain.Position(chain.NotConnected)
 
 	for _, pair := range chainPairs {
 		h, f := pair.Hops()
@@ -97,10 +110,11 @@ func (n Node) WebOfTrustInfo(their Node) WebOfTrust {
 
 			if fromRoot == chain.NotConnected || f < fromRoot {
 				fromRoot = f
+				position = pair.Chain1.PositionAt(f)
 			}
 		}
 	}
-	return WebOfTrust{Hops: hops, CommonInvider: fromRoot}
+	return WebOfTrust{Hops: hops, CommonInvider: fromRoot, Position: position}
 }
 
 func (n Node) IsInviterFor(their Node) bool {
@@ -134,6 +148,15 @@ func (n Node) CommonChain(their Node) chain.Chain {
 	return chain.Nil
 }
 
+// String returns compact, human-readable presentation of the node's chains.
+func (n Node) String() string {
+	chains := make([]string, 0, n.Len())
+	for _, c := range n.Chains {
+		chains = append(chains, c.String())
+	}
+	return "node[" + strings.Join(chains, " ") + "]"
+}
+
 func (n Node) Len() int {
 	return len(n.Chains)
 }
@@ -1,10 +1,13 @@
 package node
 
 import (
+	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
 	"github.com/lainio/ic/chain"
 	"github.com/lainio/ic/crypto"
 )
@@ -44,6 +47,8 @@ func setup() {
 	carol.Key = crypto.NewKey()
 	dave.Key = crypto.NewKey()
 	eve.Key = crypto.NewKey()
+	frank.Key = crypto.NewKey()
+	grace.Key = crypto.NewKey()
 
 	root1.Node = NewRootNode(root1.PubKey)
 	root2.Node = NewRootNode(root2.PubKey)
@@ -153,6 +158,7 @@ func TestWebOfTrustInfo(t *testing.T) {
 	wot = NewWebOfTrust(bob.Node, carol.Node)
 	assert.Equal(chain.NotConnected, wot.CommonInvider)
 	assert.Equal(chain.NotConnected, wot.Hops)
+	assert.Equal(chain.Position(chain.NotConnected), wot.Position)
 
 	frank.Node = alice.Invite(frank.Node, alice.Key, frank.PubKey, 1)
 	assert.Equal(frank.Len(), 1)
@@ -172,6 +178,7 @@ func TestWebOfTrustInfo(t *testing.T) {
 	wot = NewWebOfTrust(frank.Node, grace.Node)
 	assert.Equal(1, wot.CommonInvider)
 	assert.Equal(3, wot.Hops)
+	assert.Equal(chain.AdminPosition, wot.Position, "alice is admin")
 
 	root3 := entity{Key: crypto.NewKey()}
 	root3.Node = NewRootNode(root3.PubKey)
@@ -196,3 +203,37 @@ func TestWebOfTrustInfo(t *testing.T) {
 	assert.That(eve.IsInviterFor(heidi.Node))
 	assert.That(heidi.OneHop(eve.Node))
 }
+
+func TestNodeJSON(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	d := try.To1(json.Marshal(dave.Node))
+	var n Node
+	try.To(json.Unmarshal(d, &n))
+	assert.Equal(n.Len(), dave.Len())
+	for _, c := range n.Chains {
+		assert.That(c.Verify())
+	}
+	assert.Equal(n.String(), dave.String())
+	assert.That(strings.HasPrefix(n.String(), "node[chain[root="))
+}
+
+func TestRotate(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	ivan := entity{Key: crypto.NewKey()}
+	ivan.Node = eve.Invite(ivan.Node, eve.Key, ivan.PubKey, 1)
+	wot := NewWebOfTrust(eve.Node, ivan.Node)
+
+	newKey := crypto.NewKey()
+	rotated := eve.Rotate(eve.Key, newKey.PubKey)
+	assert.Equal(rotated.Len(), eve.Len())
+	for _, c := range rotated.Chains {
+		assert.That(c.Verify())
+		assert.DeepEqual(c.LeafPubKey(), newKey.PubKey)
+	}
+	assert.Equal(NewWebOfTrust(rotated, ivan.Node), wot)
+	assert.That(rotated.IsInviterFor(ivan.Node))
+	assert.Equal(NewWebOfTrust(rotated, dave.Node),
+		NewWebOfTrust(eve.Node, dave.Node))
+}
@@ -0,0 +1,168 @@
+// Package reputation computes numeric trust scores on top of the web-of-trust
+// information of the nodes. Scorer is the interface for the scoring
+// algorithms, and DefaultScorer is the one for the most common needs.
+package reputation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+// Scorer computes the trust score of their node from my node's point of view.
+type Scorer interface {
+	Score(my, their node.Node) Score
+}
+
+// Score is the trust score and its explanation.
+type Score struct {
+	// Value is between 0, no trust at all, and 1, full trust.
+	Value float64
+
+	// Roots are the scores of the shared webs-of-trust, the best first.
+	Roots []RootScore
+}
+
+// RootScore is the score of their node in one shared web-of-trust.
+type RootScore struct {
+	Root     string // fingerprint of the root key
+	Hops     int
+	Depth    int            // their distance from the root
+	Position chain.Position // their position
+	Revoked  bool           // either of the chains is revoked
+	Value    float64
+}
+
+func (r RootScore) String() string {
+	if r.Revoked {
+		return fmt.Sprintf("root %s: revoked, score=0", r.Root)
+	}
+	return fmt.Sprintf("root %s: hops=%d depth=%d position=%v score=%.3f",
+		r.Root, r.Hops, r.Depth, r.Position, r.Value)
+}
+
+// Explain returns human-readable explanation of the score, one line per
+// shared web-of-trust.
+func (s Score) Explain() string {
+	if len(s.Roots) == 0 {
+		return fmt.Sprintf("score=%.3f: no shared webs-of-trust", s.Value)
+	}
+	lines := make([]string, 0, len(s.Roots)+1)
+	lines = append(lines, fmt.Sprintf("score=%.3f from %d shared roots",
+		s.Value, len(s.Roots)))
+	for _, r := range s.Roots {
+		lines = append(lines, "  "+r.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Default parameters of DefaultScorer.
+const (
+	DefaultHopDecay   = 0.5
+	DefaultDepthDecay = 0.9
+)
+
+// DefaultPositionWeights are the default weights of the predefined positions.
+// Other positions get the weight of the GuestPosition halved.
+var DefaultPositionWeights = map[chain.Position]float64{
+	chain.RootPosition:   1.0,
+	chain.AdminPosition:  0.9,
+	chain.MemberPosition: 0.7,
+	chain.GuestPosition:  0.4,
+}
+
+// DefaultScorer scores every shared web-of-trust separately and combines them.
+// The score of a web-of-trust is:
+//
+//	HopDecay^(hops-1) * DepthDecay^depth * PositionWeights[position]
+//
+// where the depth and position are their node's. Revoked chains score zero,
+// and the chains that don't verify otherwise aren't scored at all.
+// The scores of the independent webs-of-trust are combined like independent
+// probabilities: 1 - (1-s1)(1-s2)..., i.e. every shared root adds trust but
+// the score never exceeds 1. The zero value uses the defaults.
+type DefaultScorer struct {
+	HopDecay        float64
+	DepthDecay      float64
+	PositionWeights map[chain.Position]float64
+	Revocations     chain.RevocationSet
+}
+
+var _ Scorer = DefaultScorer{}
+
+func (s DefaultScorer) Score(my, their node.Node) Score {
+	var score Score
+	distrust := 1.0
+	for _, p := range my.CommonChains(their) {
+		// pairs are my chain first
+		r, ok := s.rootScore(p.Chain1, p.Chain2)
+		if !ok || r.Hops == chain.NotConnected {
+			continue
+		}
+		score.Roots = append(score.Roots, r)
+		distrust *= 1 - r.Value
+	}
+	score.Value = 1 - distrust
+	sort.SliceStable(score.Roots, func(i, j int) bool {
+		return score.Roots[i].Value > score.Roots[j].Value
+	})
+	return score
+}
+
+// rootScore returns the score of the chain pair. It returns false if either
+// of the chains is invalid for other reason than revocation.
+func (s DefaultScorer) rootScore(my, their chain.Chain) (RootScore, bool) {
+	r := RootScore{
+		Root:     crypto.Fingerprint(their.Blocks[0].InviteePubKey),
+		Depth:    their.Depth(),
+		Position: their.Position(),
+	}
+	r.Hops, _ = chain.Hops(my, their)
+	for _, c := range []chain.Chain{my, their} {
+		switch c.VerifyReportWith(s.Revocations).Failure {
+		case chain.FailNone:
+		case chain.FailRevoked:
+			r.Revoked = true
+		default:
+			return r, false
+		}
+	}
+	if r.Revoked {
+		return r, true
+	}
+	hops := math.Max(float64(r.Hops-1), 0)
+	r.Value = math.Pow(s.hopDecay(), hops) *
+		math.Pow(s.depthDecay(), float64(r.Depth)) *
+		s.positionWeight(r.Position)
+	return r, true
+}
+
+func (s DefaultScorer) hopDecay() float64 {
+	if s.HopDecay == 0 {
+		return DefaultHopDecay
+	}
+	return s.HopDecay
+}
+
+func (s DefaultScorer) depthDecay() float64 {
+	if s.DepthDecay == 0 {
+		return DefaultDepthDecay
+	}
+	return s.DepthDecay
+}
+
+func (s DefaultScorer) positionWeight(p chain.Position) float64 {
+	weights := s.PositionWeights
+	if weights == nil {
+		weights = DefaultPositionWeights
+	}
+	if w, ok := weights[p]; ok {
+		return w
+	}
+	return weights[chain.GuestPosition] / 2
+}
@@ -0,0 +1,146 @@
+package reputation
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+type entity struct {
+	crypto.Key
+	node.Node
+}
+
+func newRoot() (e entity) {
+	e.Key = crypto.NewKey()
+	e.Node = node.NewRootNode(e.PubKey)
+	return e
+}
+
+// invite returns the invitee which is invited by all the inviters.
+func invite(position chain.Position, inviters ...entity) (e entity) {
+	e.Key = crypto.NewKey()
+	for _, inviter := range inviters {
+		e.Node = inviter.Invite(e.Node, inviter.Key, e.PubKey, position)
+	}
+	return e
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDefaultScorer(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	// root -> alice -> bob -> carol, root -> dave (guest)
+	root := newRoot()
+	alice := invite(chain.AdminPosition, root)
+	bob := invite(chain.MemberPosition, alice)
+	carol := invite(chain.MemberPosition, bob)
+	dave := invite(chain.GuestPosition, root)
+
+	var s DefaultScorer
+	ab := s.Score(alice.Node, bob.Node)
+	assert.Equal(ab.Roots[0].Hops, 1)
+	assert.Equal(ab.Roots[0].Depth, 2)
+	assert.Equal(ab.Roots[0].Position, chain.MemberPosition)
+	assert.That(near(ab.Value, 0.9*0.9*0.7))
+
+	// farther is less trusted
+	ac := s.Score(alice.Node, carol.Node)
+	assert.That(ac.Value < ab.Value)
+
+	// so are the worse positions
+	ad := s.Score(alice.Node, dave.Node)
+	ab2 := s.Score(alice.Node, invite(chain.MemberPosition, root).Node)
+	assert.Equal(ad.Roots[0].Hops, ab2.Roots[0].Hops)
+	assert.That(ad.Value < ab2.Value)
+
+	// strangers get nothing
+	st := s.Score(alice.Node, newRoot().Node)
+	assert.Equal(st.Value, 0.0)
+	assert.SLen(st.Roots, 0)
+	assert.That(strings.Contains(st.Explain(), "no shared"))
+}
+
+func TestIndependentRoots(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	root1, root2 := newRoot(), newRoot()
+	alice := invite(chain.MemberPosition, root1, root2)
+	bob := invite(chain.MemberPosition, root1)
+	carol := invite(chain.MemberPosition, root1, root2)
+
+	var s DefaultScorer
+	one := s.Score(alice.Node, bob.Node)
+	two := s.Score(alice.Node, carol.Node)
+	assert.SLen(one.Roots, 1)
+	assert.SLen(two.Roots, 2)
+	assert.That(two.Value > one.Value)
+	assert.That(two.Value <= 1)
+	assert.That(near(two.Value, 1-(1-one.Value)*(1-one.Value)))
+	assert.Equal(len(strings.Split(two.Explain(), "\n")), 3)
+}
+
+func TestRevocations(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	root1, root2 := newRoot(), newRoot()
+	alice := invite(chain.MemberPosition, root1, root2)
+	bob := invite(chain.MemberPosition, root1, root2)
+
+	s := DefaultScorer{}
+	before := s.Score(alice.Node, bob.Node)
+
+	// root1 revokes bob
+	s.Revocations = chain.NewRevocationSet(
+		chain.Revoke(root1.Key, bob.CommonChain(root1.Node)))
+	after := s.Score(alice.Node, bob.Node)
+	assert.SLen(after.Roots, 2)
+	assert.That(after.Value < before.Value)
+	assert.That(after.Value > 0, "root2 still trusts")
+	assert.That(after.Roots[1].Revoked, "revoked is the worst")
+	assert.That(strings.Contains(after.Explain(), "revoked"))
+}
+
+func TestCustomScorer(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	root := newRoot()
+	alice := invite(chain.MemberPosition, root)
+	bob := invite(chain.MemberPosition, root)
+
+	s := DefaultScorer{
+		HopDecay:        1,
+		DepthDecay:      1,
+		PositionWeights: map[chain.Position]float64{chain.MemberPosition: 1},
+	}
+	var scorer Scorer = s
+	assert.That(near(scorer.Score(alice.Node, bob.Node).Value, 1))
+}
+
+func TestInvalidChain(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	root1, root2 := newRoot(), newRoot()
+	alice := invite(chain.MemberPosition, root1, root2)
+	bob := invite(chain.MemberPosition, root1, root2)
+
+	// break the signature of bob's leaf block in root1's web-of-trust
+	c := bob.CommonChain(root1.Node)
+	leaf := &c.Blocks[len(c.Blocks)-1]
+	leaf.InvitersSignature = append([]byte{}, leaf.InvitersSignature...)
+	leaf.InvitersSignature[0] ^= 0x01
+
+	var s DefaultScorer
+	score := s.Score(alice.Node, bob.Node)
+	assert.SLen(score.Roots, 1, "invalid chain isn't scored")
+	assert.That(!score.Roots[0].Revoked)
+	assert.That(!strings.Contains(score.Explain(), "revoked"))
+}
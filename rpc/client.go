@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+	"google.golang.org/grpc"
+)
+
+// Client is a Go client of the InvitationService. It converts the messages to
+// the chain and node types. The errors from the server are gRPC status errors,
+// use status.Code to check them.
+type Client struct {
+	c InvitationServiceClient
+}
+
+// NewClient returns a client which uses the connection cc.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{c: NewInvitationServiceClient(cc)}
+}
+
+// Invite asks the server to invite the inviteesPubKey to the chain c. The
+// chain's leaf key must be the server key.
+func (cl *Client) Invite(
+	ctx context.Context,
+	c chain.Chain,
+	inviteesPubKey crypto.PubKey,
+	position chain.Position,
+) (nc chain.Chain, err error) {
+	defer err2.Handle(&err)
+
+	resp := try.To1(cl.c.Invite(ctx, &InviteRequest{
+		Chain:         try.To1(c.TryBytes()),
+		InviteePubKey: inviteesPubKey,
+		Position:      int64(position),
+	}))
+	return chain.ParseChain(resp.Chain)
+}
+
+// Verify asks the server to verify the chain c.
+func (cl *Client) Verify(
+	ctx context.Context,
+	c chain.Chain,
+) (r chain.VerifyReport, err error) {
+	defer err2.Handle(&err)
+
+	resp := try.To1(cl.c.Verify(ctx, &VerifyRequest{
+		Chain: try.To1(c.TryBytes()),
+	}))
+	return chain.VerifyReport{
+		Block:   int(resp.Block),
+		Failure: chain.Failure(resp.Failure),
+	}, nil
+}
+
+// Challenge proves to the server that we hold the responder's chain. The
+// server is the expected identity of the server, i.e. the audience of the
+// challenge, see challenge.Responder.
+func (cl *Client) Challenge(
+	ctx context.Context,
+	r challenge.Responder,
+	server crypto.PubKey,
+) (err error) {
+	defer err2.Handle(&err)
+
+	d := try.To1(r.Chain().TryBytes())
+	resp := try.To1(cl.c.NewChallenge(ctx, &NewChallengeRequest{Chain: d}))
+	rr := try.To1(r.Respond(fromChallenge(resp.Challenge), server))
+	try.To1(cl.c.VerifyChallenge(ctx, &VerifyChallengeRequest{
+		Chain:     d,
+		Challenge: resp.Challenge,
+		Signature: rr.Signature,
+	}))
+	return nil
+}
+
+// NodeInvite asks the server node to invite the invitee node. It returns the
+// invitee's new node, see node.Node.Invite.
+func (cl *Client) NodeInvite(
+	ctx context.Context,
+	invitee node.Node,
+	inviteesPubKey crypto.PubKey,
+	position chain.Position,
+) (n node.Node, err error) {
+	defer err2.Handle(&err)
+
+	resp := try.To1(cl.c.NodeInvite(ctx, &NodeInviteRequest{
+		Invitee:       try.To1(toNode(invitee)),
+		InviteePubKey: inviteesPubKey,
+		Position:      int64(position),
+	}))
+	return fromNode(resp.Node)
+}
+
+// WebOfTrustInfo returns the web-of-trust information between the server node
+// and their node.
+func (cl *Client) WebOfTrustInfo(
+	ctx context.Context,
+	their node.Node,
+) (wot node.WebOfTrust, err error) {
+	defer err2.Handle(&err)
+
+	resp := try.To1(cl.c.WebOfTrustInfo(ctx, &WebOfTrustInfoRequest{
+		Node: try.To1(toNode(their)),
+	}))
+	return node.WebOfTrust{
+		Hops:          int(resp.Hops),
+		CommonInvider: int(resp.CommonInviter),
+		Position:      chain.Position(resp.Position),
+	}, nil
+}
+
+// CommonChains returns the chain pairs the server node and their node share.
+// Chain1 of the pairs is the server's chain.
+func (cl *Client) CommonChains(
+	ctx context.Context,
+	their node.Node,
+) (pairs []chain.Pair, err error) {
+	defer err2.Handle(&err)
+
+	resp := try.To1(cl.c.CommonChains(ctx, &CommonChainsRequest{
+		Node: try.To1(toNode(their)),
+	}))
+	pairs = make([]chain.Pair, 0, len(resp.Pairs))
+	for _, p := range resp.Pairs {
+		pairs = append(pairs, chain.Pair{
+			Chain1: try.To1(chain.ParseChain(p.Chain1)),
+			Chain2: try.To1(chain.ParseChain(p.Chain2)),
+		})
+	}
+	return pairs, nil
+}
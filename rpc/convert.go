@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/node"
+)
+
+func toNode(n node.Node) (_ *Node, err error) {
+	defer err2.Handle(&err)
+
+	pn := &Node{Chains: make([][]byte, 0, n.Len())}
+	for _, c := range n.Chains {
+		pn.Chains = append(pn.Chains, try.To1(c.TryBytes()))
+	}
+	return pn, nil
+}
+
+// fromNode decodes the node's chains. The chains aren't verified here, the
+// node methods do it.
+func fromNode(pn *Node) (n node.Node, err error) {
+	defer err2.Handle(&err)
+
+	n.Chains = make([]chain.Chain, 0, len(pn.GetChains()))
+	for _, d := range pn.GetChains() {
+		n.Chains = append(n.Chains, try.To1(chain.ParseChain(d)))
+	}
+	return n, nil
+}
+
+func toChallenge(ch challenge.Challenge) *Challenge {
+	return &Challenge{
+		Nonce:    ch.Nonce,
+		Verifier: ch.Verifier,
+		Subject:  ch.Subject,
+		Expires:  ch.Expires.Unix(),
+	}
+}
+
+func fromChallenge(pc *Challenge) challenge.Challenge {
+	return challenge.Challenge{
+		Nonce:    pc.GetNonce(),
+		Verifier: pc.GetVerifier(),
+		Subject:  pc.GetSubject(),
+		Expires:  time.Unix(pc.GetExpires(), 0),
+	}
+}
@@ -0,0 +1,361 @@
+// Invitation Chain gRPC API. Chains are carried in their canonical binary
+// encoding, see chain.EncodingVersion in github.com/lainio/ic/chain, i.e. the
+// clients don't need to know the block structure to use the API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: ic.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	InvitationService_Invite_FullMethodName          = "/ic.v1.InvitationService/Invite"
+	InvitationService_Verify_FullMethodName          = "/ic.v1.InvitationService/Verify"
+	InvitationService_NewChallenge_FullMethodName    = "/ic.v1.InvitationService/NewChallenge"
+	InvitationService_VerifyChallenge_FullMethodName = "/ic.v1.InvitationService/VerifyChallenge"
+	InvitationService_NodeInvite_FullMethodName      = "/ic.v1.InvitationService/NodeInvite"
+	InvitationService_WebOfTrustInfo_FullMethodName  = "/ic.v1.InvitationService/WebOfTrustInfo"
+	InvitationService_CommonChains_FullMethodName    = "/ic.v1.InvitationService/CommonChains"
+)
+
+// InvitationServiceClient is the client API for InvitationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InvitationServiceClient interface {
+	// Invite adds a block for the invitee to the chain. The chain's leaf key
+	// must be the server key.
+	Invite(ctx context.Context, in *InviteRequest, opts ...grpc.CallOption) (*InviteResponse, error)
+	// Verify verifies the chain and reports the first failure.
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// NewChallenge returns a challenge for the holder of the chain. The holder
+	// signs it and sends the response with VerifyChallenge.
+	NewChallenge(ctx context.Context, in *NewChallengeRequest, opts ...grpc.CallOption) (*NewChallengeResponse, error)
+	// VerifyChallenge verifies the response to the challenge. Every challenge
+	// can be answered only once.
+	VerifyChallenge(ctx context.Context, in *VerifyChallengeRequest, opts ...grpc.CallOption) (*VerifyChallengeResponse, error)
+	// NodeInvite invites the node to all of the server node's webs-of-trust
+	// that the node doesn't belong yet.
+	NodeInvite(ctx context.Context, in *NodeInviteRequest, opts ...grpc.CallOption) (*NodeInviteResponse, error)
+	// WebOfTrustInfo returns the web-of-trust information between the server
+	// node and the node.
+	WebOfTrustInfo(ctx context.Context, in *WebOfTrustInfoRequest, opts ...grpc.CallOption) (*WebOfTrustInfoResponse, error)
+	// CommonChains returns the chain pairs which the server node and the node
+	// share.
+	CommonChains(ctx context.Context, in *CommonChainsRequest, opts ...grpc.CallOption) (*CommonChainsResponse, error)
+}
+
+type invitationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInvitationServiceClient(cc grpc.ClientConnInterface) InvitationServiceClient {
+	return &invitationServiceClient{cc}
+}
+
+func (c *invitationServiceClient) Invite(ctx context.Context, in *InviteRequest, opts ...grpc.CallOption) (*InviteResponse, error) {
+	out := new(InviteResponse)
+	err := c.cc.Invoke(ctx, InvitationService_Invite_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, InvitationService_Verify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) NewChallenge(ctx context.Context, in *NewChallengeRequest, opts ...grpc.CallOption) (*NewChallengeResponse, error) {
+	out := new(NewChallengeResponse)
+	err := c.cc.Invoke(ctx, InvitationService_NewChallenge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) VerifyChallenge(ctx context.Context, in *VerifyChallengeRequest, opts ...grpc.CallOption) (*VerifyChallengeResponse, error) {
+	out := new(VerifyChallengeResponse)
+	err := c.cc.Invoke(ctx, InvitationService_VerifyChallenge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) NodeInvite(ctx context.Context, in *NodeInviteRequest, opts ...grpc.CallOption) (*NodeInviteResponse, error) {
+	out := new(NodeInviteResponse)
+	err := c.cc.Invoke(ctx, InvitationService_NodeInvite_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) WebOfTrustInfo(ctx context.Context, in *WebOfTrustInfoRequest, opts ...grpc.CallOption) (*WebOfTrustInfoResponse, error) {
+	out := new(WebOfTrustInfoResponse)
+	err := c.cc.Invoke(ctx, InvitationService_WebOfTrustInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invitationServiceClient) CommonChains(ctx context.Context, in *CommonChainsRequest, opts ...grpc.CallOption) (*CommonChainsResponse, error) {
+	out := new(CommonChainsResponse)
+	err := c.cc.Invoke(ctx, InvitationService_CommonChains_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InvitationServiceServer is the server API for InvitationService service.
+// All implementations must embed UnimplementedInvitationServiceServer
+// for forward compatibility
+type InvitationServiceServer interface {
+	// Invite adds a block for the invitee to the chain. The chain's leaf key
+	// must be the server key.
+	Invite(context.Context, *InviteRequest) (*InviteResponse, error)
+	// Verify verifies the chain and reports the first failure.
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// NewChallenge returns a challenge for the holder of the chain. The holder
+	// signs it and sends the response with VerifyChallenge.
+	NewChallenge(context.Context, *NewChallengeRequest) (*NewChallengeResponse, error)
+	// VerifyChallenge verifies the response to the challenge. Every challenge
+	// can be answered only once.
+	VerifyChallenge(context.Context, *VerifyChallengeRequest) (*VerifyChallengeResponse, error)
+	// NodeInvite invites the node to all of the server node's webs-of-trust
+	// that the node doesn't belong yet.
+	NodeInvite(context.Context, *NodeInviteRequest) (*NodeInviteResponse, error)
+	// WebOfTrustInfo returns the web-of-trust information between the server
+	// node and the node.
+	WebOfTrustInfo(context.Context, *WebOfTrustInfoRequest) (*WebOfTrustInfoResponse, error)
+	// CommonChains returns the chain pairs which the server node and the node
+	// share.
+	CommonChains(context.Context, *CommonChainsRequest) (*CommonChainsResponse, error)
+	mustEmbedUnimplementedInvitationServiceServer()
+}
+
+// UnimplementedInvitationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedInvitationServiceServer struct {
+}
+
+func (UnimplementedInvitationServiceServer) Invite(context.Context, *InviteRequest) (*InviteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Invite not implemented")
+}
+func (UnimplementedInvitationServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedInvitationServiceServer) NewChallenge(context.Context, *NewChallengeRequest) (*NewChallengeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewChallenge not implemented")
+}
+func (UnimplementedInvitationServiceServer) VerifyChallenge(context.Context, *VerifyChallengeRequest) (*VerifyChallengeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyChallenge not implemented")
+}
+func (UnimplementedInvitationServiceServer) NodeInvite(context.Context, *NodeInviteRequest) (*NodeInviteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NodeInvite not implemented")
+}
+func (UnimplementedInvitationServiceServer) WebOfTrustInfo(context.Context, *WebOfTrustInfoRequest) (*WebOfTrustInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebOfTrustInfo not implemented")
+}
+func (UnimplementedInvitationServiceServer) CommonChains(context.Context, *CommonChainsRequest) (*CommonChainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommonChains not implemented")
+}
+func (UnimplementedInvitationServiceServer) mustEmbedUnimplementedInvitationServiceServer() {}
+
+// UnsafeInvitationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InvitationServiceServer will
+// result in compilation errors.
+type UnsafeInvitationServiceServer interface {
+	mustEmbedUnimplementedInvitationServiceServer()
+}
+
+func RegisterInvitationServiceServer(s grpc.ServiceRegistrar, srv InvitationServiceServer) {
+	s.RegisterService(&InvitationService_ServiceDesc, srv)
+}
+
+func _InvitationService_Invite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).Invite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_Invite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).Invite(ctx, req.(*InviteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_NewChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).NewChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_NewChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).NewChallenge(ctx, req.(*NewChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_VerifyChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).VerifyChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_VerifyChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).VerifyChallenge(ctx, req.(*VerifyChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_NodeInvite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeInviteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).NodeInvite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_NodeInvite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).NodeInvite(ctx, req.(*NodeInviteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_WebOfTrustInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebOfTrustInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).WebOfTrustInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_WebOfTrustInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).WebOfTrustInfo(ctx, req.(*WebOfTrustInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvitationService_CommonChains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommonChainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvitationServiceServer).CommonChains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvitationService_CommonChains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvitationServiceServer).CommonChains(ctx, req.(*CommonChainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InvitationService_ServiceDesc is the grpc.ServiceDesc for InvitationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InvitationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ic.v1.InvitationService",
+	HandlerType: (*InvitationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Invite",
+			Handler:    _InvitationService_Invite_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _InvitationService_Verify_Handler,
+		},
+		{
+			MethodName: "NewChallenge",
+			Handler:    _InvitationService_NewChallenge_Handler,
+		},
+		{
+			MethodName: "VerifyChallenge",
+			Handler:    _InvitationService_VerifyChallenge_Handler,
+		},
+		{
+			MethodName: "NodeInvite",
+			Handler:    _InvitationService_NodeInvite_Handler,
+		},
+		{
+			MethodName: "WebOfTrustInfo",
+			Handler:    _InvitationService_WebOfTrustInfo_Handler,
+		},
+		{
+			MethodName: "CommonChains",
+			Handler:    _InvitationService_CommonChains_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ic.proto",
+}
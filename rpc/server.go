@@ -0,0 +1,193 @@
+// Package rpc implements the gRPC API of the Invitation Chain, see ic.proto.
+// Server runs the service for the holder of the server key and its node, and
+// Client is a Go client that converts the messages to the chain and node
+// types.
+package rpc
+
+//go:generate sh -c "cd ../tools/protogen && go run . ../../rpc ic.proto"
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements InvitationServiceServer. It's safe for concurrent use.
+type Server struct {
+	UnimplementedInvitationServiceServer
+
+	key      crypto.KeyHandle
+	node     node.Node
+	verifier *challenge.Verifier
+}
+
+// NewServer returns a server that invites with the key and answers the
+// web-of-trust queries for the node n. The key must be the leaf key of the
+// node's chains. The key's public key is the audience of the challenges.
+func NewServer(key crypto.KeyHandle, n node.Node) *Server {
+	return &Server{
+		key:      key,
+		node:     n,
+		verifier: challenge.NewVerifier(key.PublicKey(), 0),
+	}
+}
+
+// Register registers the server to the gRPC server gs.
+func (s *Server) Register(gs *grpc.Server) {
+	RegisterInvitationServiceServer(gs, s)
+}
+
+func (s *Server) Invite(
+	_ context.Context,
+	req *InviteRequest,
+) (_ *InviteResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	c := try.To1(chain.ParseChain(req.Chain))
+	try.To(c.TryVerify())
+	nc := try.To1(c.TryInvite(s.key, req.InviteePubKey,
+		chain.Position(req.Position)))
+	return &InviteResponse{Chain: try.To1(nc.TryBytes())}, nil
+}
+
+func (s *Server) Verify(
+	_ context.Context,
+	req *VerifyRequest,
+) (_ *VerifyResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	c := try.To1(chain.ParseChain(req.Chain))
+	r := c.VerifyReport()
+	if req.At != 0 {
+		r = c.VerifyReportAt(time.Unix(req.At, 0))
+	}
+	resp := &VerifyResponse{
+		Ok:      r.OK(),
+		Block:   int32(r.Block),
+		Failure: int32(r.Failure),
+	}
+	if !r.OK() {
+		resp.Error = r.Err().Error()
+	}
+	return resp, nil
+}
+
+func (s *Server) NewChallenge(
+	_ context.Context,
+	req *NewChallengeRequest,
+) (_ *NewChallengeResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	c := try.To1(chain.ParseChain(req.Chain))
+	ch := try.To1(s.verifier.New(c))
+	return &NewChallengeResponse{Challenge: toChallenge(ch)}, nil
+}
+
+func (s *Server) VerifyChallenge(
+	_ context.Context,
+	req *VerifyChallengeRequest,
+) (_ *VerifyChallengeResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	c := try.To1(chain.ParseChain(req.Chain))
+	try.To(s.verifier.Verify(c, challenge.Response{
+		Challenge: fromChallenge(req.Challenge),
+		Signature: req.Signature,
+	}))
+	return &VerifyChallengeResponse{}, nil
+}
+
+func (s *Server) NodeInvite(
+	_ context.Context,
+	req *NodeInviteRequest,
+) (_ *NodeInviteResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	invitee := try.To1(fromNode(req.Invitee))
+	n := s.node.Invite(invitee, s.key, req.InviteePubKey,
+		chain.Position(req.Position))
+	return &NodeInviteResponse{Node: try.To1(toNode(n))}, nil
+}
+
+func (s *Server) WebOfTrustInfo(
+	_ context.Context,
+	req *WebOfTrustInfoRequest,
+) (_ *WebOfTrustInfoResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	their := try.To1(fromNode(req.Node))
+	wot := s.node.WebOfTrustInfo(their)
+	return &WebOfTrustInfoResponse{
+		Hops:          int64(wot.Hops),
+		CommonInviter: int64(wot.CommonInvider),
+		Position:      int64(wot.Position),
+	}, nil
+}
+
+func (s *Server) CommonChains(
+	_ context.Context,
+	req *CommonChainsRequest,
+) (_ *CommonChainsResponse, err error) {
+	defer err2.Handle(&err, toStatus)
+
+	their := try.To1(fromNode(req.Node))
+	pairs := s.node.CommonChains(their)
+	resp := &CommonChainsResponse{Pairs: make([]*ChainPair, 0, len(pairs))}
+	for _, p := range pairs {
+		resp.Pairs = append(resp.Pairs, &ChainPair{
+			Chain1: try.To1(p.Chain1.TryBytes()),
+			Chain2: try.To1(p.Chain2.TryBytes()),
+		})
+	}
+	return resp, nil
+}
+
+// errCodes maps the sentinel errors to gRPC status codes. The first match wins.
+var errCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{chain.ErrDecode, codes.InvalidArgument},
+	{chain.ErrEmptyChain, codes.InvalidArgument},
+	{challenge.ErrMalformed, codes.InvalidArgument},
+	{chain.ErrNotLeaf, codes.PermissionDenied},
+	{chain.ErrPosition, codes.PermissionDenied},
+	{chain.ErrBadRoot, codes.FailedPrecondition},
+	{chain.ErrHashLink, codes.FailedPrecondition},
+	{chain.ErrBadSignature, codes.FailedPrecondition},
+	{chain.ErrRotation, codes.FailedPrecondition},
+	{chain.ErrAcceptance, codes.FailedPrecondition},
+	{chain.ErrAlgorithm, codes.FailedPrecondition},
+	{chain.ErrExpired, codes.FailedPrecondition},
+	{chain.ErrRevoked, codes.FailedPrecondition},
+	{challenge.ErrExpired, codes.Unauthenticated},
+	{challenge.ErrUnknownNonce, codes.Unauthenticated},
+	{challenge.ErrReplay, codes.Unauthenticated},
+	{challenge.ErrAudience, codes.Unauthenticated},
+	{challenge.ErrSubject, codes.Unauthenticated},
+	{challenge.ErrBadSignature, codes.Unauthenticated},
+	{challenge.ErrChain, codes.Unauthenticated},
+}
+
+// toStatus converts the error to a gRPC status error. It's the error handler of
+// the server methods.
+func toStatus(err error) error {
+	code := codes.Internal
+	for _, ec := range errCodes {
+		if errors.Is(err, ec.err) {
+			code = ec.code
+			break
+		}
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/challenge"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	rootKey crypto.Key
+	client  *Client
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	teardown := setup()
+	code := m.Run()
+	teardown()
+	os.Exit(code)
+}
+
+// setup starts the server of the root node in-process.
+func setup() (teardown func()) {
+	rootKey = crypto.NewKey()
+	srv := NewServer(rootKey.Handle(), node.NewRootNode(rootKey.PubKey))
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	srv.Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+
+	cc := try.To1(grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	))
+	client = NewClient(cc)
+	return func() {
+		cc.Close()
+		gs.Stop()
+	}
+}
+
+func TestInvite(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	aliceKey := crypto.NewKey()
+	root := chain.NewRootChain(rootKey.PubKey)
+	alice := try.To1(client.Invite(ctx, root, aliceKey.PubKey, 1))
+	assert.That(alice.Verify())
+	assert.DeepEqual(alice.LeafPubKey(), aliceKey.PubKey)
+
+	// the server can invite only with its own key
+	_, err := client.Invite(ctx, alice, crypto.NewKey().PubKey, 1)
+	assert.Equal(status.Code(err), codes.PermissionDenied)
+	_, err = client.Invite(ctx, root, crypto.NewKey().PubKey, -1)
+	assert.Equal(status.Code(err), codes.PermissionDenied)
+
+	// and only to valid chains
+	broken := alice.Clone()
+	broken.Blocks[1].Position = 2
+	_, err = client.Invite(ctx, broken.Clone(), crypto.NewKey().PubKey, 2)
+	assert.Equal(status.Code(err), codes.FailedPrecondition)
+	broken = root.Clone()
+	broken.Blocks[0].Algorithm = crypto.P256
+	_, err = client.Invite(ctx, broken, crypto.NewKey().PubKey, 1)
+	assert.Equal(status.Code(err), codes.FailedPrecondition)
+	_, err = client.Invite(ctx, root, aliceKey.PubKey[:5], 1)
+	assert.Equal(status.Code(err), codes.FailedPrecondition)
+}
+
+func TestVerify(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	aliceKey := crypto.NewKey()
+	alice := chain.NewRootChain(rootKey.PubKey).
+		Invite(rootKey, aliceKey.PubKey, 1)
+	r := try.To1(client.Verify(ctx, alice))
+	assert.That(r.OK())
+
+	alice.Blocks[1].InvitersSignature[0] ^= 0x01
+	r = try.To1(client.Verify(ctx, alice))
+	assert.Equal(r.Failure, chain.FailSignature)
+	assert.Equal(r.Block, 1)
+
+	_, err := client.Verify(ctx, chain.Nil)
+	assert.Equal(status.Code(err), codes.InvalidArgument)
+}
+
+func TestChallenge(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	aliceKey := crypto.NewKey()
+	alice := chain.NewRootChain(rootKey.PubKey).
+		Invite(rootKey, aliceKey.PubKey, 1)
+	r := try.To1(challenge.NewResponder(aliceKey.Handle(), alice))
+	assert.NoError(client.Challenge(ctx, r, rootKey.PubKey))
+
+	// a response signed by other key
+	d := alice.Bytes()
+	resp := try.To1(client.c.NewChallenge(ctx, &NewChallengeRequest{Chain: d}))
+	input := fromChallenge(resp.Challenge).SigningInput()
+	_, err := client.c.VerifyChallenge(ctx, &VerifyChallengeRequest{
+		Chain:     d,
+		Challenge: resp.Challenge,
+		Signature: crypto.NewKey().Sign(input),
+	})
+	assert.Equal(status.Code(err), codes.Unauthenticated)
+
+	// the challenge was consumed
+	_, err = client.c.VerifyChallenge(ctx, &VerifyChallengeRequest{
+		Chain:     d,
+		Challenge: resp.Challenge,
+		Signature: aliceKey.Sign(input),
+	})
+	assert.Equal(status.Code(err), codes.Unauthenticated)
+
+	// wrong audience is refused by the responder itself
+	err = client.Challenge(ctx, r, crypto.NewKey().PubKey)
+	assert.Error(err)
+}
+
+func TestNodeQueries(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	aliceKey := crypto.NewKey()
+	alice := node.NewRootNode(aliceKey.PubKey)
+	alice = try.To1(client.NodeInvite(ctx, alice, aliceKey.PubKey, 1))
+	assert.SLen(alice.Chains, 2)
+
+	wot := try.To1(client.WebOfTrustInfo(ctx, alice))
+	assert.Equal(wot.Hops, 1)
+	assert.Equal(wot.CommonInvider, 0)
+	assert.Equal(wot.Position, chain.RootPosition)
+
+	pairs := try.To1(client.CommonChains(ctx, alice))
+	assert.SLen(pairs, 1)
+	assert.DeepEqual(pairs[0].Chain2.LeafPubKey(), aliceKey.PubKey)
+
+	stranger := node.NewRootNode(crypto.NewKey().PubKey)
+	wot = try.To1(client.WebOfTrustInfo(ctx, stranger))
+	assert.Equal(wot.Hops, chain.NotConnected)
+	assert.SLen(try.To1(client.CommonChains(ctx, stranger)), 0)
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+const (
+	chainExt    = ".chain"
+	tmpExt      = ".tmp"
+	journalName = "journal"
+)
+
+var _ Store = (*DirStore)(nil)
+
+// DirStore is the file system backend. Every chain is in its own file in the
+// directory. Puts are first written to a journal file, which is replayed when
+// the store is opened after a crash. Files are replaced atomically by
+// renaming.
+type DirStore struct {
+	index
+	dir string
+}
+
+// OpenDir opens the directory store, and creates the directory if it doesn't
+// exist. An unfinished update is completed from the journal.
+func OpenDir(dir string) (s *DirStore, err error) {
+	defer err2.Handle(&err)
+
+	try.To(os.MkdirAll(dir, 0o700))
+	s = &DirStore{index: newIndex(), dir: dir}
+	try.To(s.recover())
+	try.To(s.load())
+	return s, nil
+}
+
+func (s *DirStore) Put(chains ...chain.Chain) (err error) {
+	defer err2.Handle(&err)
+
+	ops := try.To1(putOps(chains))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	try.To(s.writable())
+	try.To(s.commit(ops))
+	s.apply(ops)
+	return nil
+}
+
+func (s *DirStore) Delete(root, leaf crypto.PubKey) (err error) {
+	defer err2.Handle(&err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	try.To(s.writable())
+	k := key(root, leaf)
+	if _, ok := s.chains[k]; !ok {
+		return ErrNotFound
+	}
+	ops := []op{{kind: opDelete, key: k}}
+	try.To(s.commit(ops))
+	s.apply(ops)
+	return nil
+}
+
+func (s *DirStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chains = nil
+	return nil
+}
+
+// commit writes the ops to the journal, applies them to the files and removes
+// the journal. If we crash after the journal is written, recover finishes the
+// job. If only the commit fails after that, the store is failed, i.e. the
+// journal isn't overwritten by the next updates before recover.
+func (s *DirStore) commit(ops []op) (err error) {
+	defer err2.Handle(&err)
+
+	journal := filepath.Join(s.dir, journalName)
+	try.To(writeFile(journal, try.To1(encodeOps(ops))))
+	defer err2.Handle(&err, func(err error) error {
+		s.fail(err)
+		return err
+	})
+	try.To(syncDir(s.dir))
+	try.To(s.write(ops))
+	try.To(os.Remove(journal))
+	return syncDir(s.dir)
+}
+
+// write applies the ops to the chain files. It's idempotent, i.e. it can be
+// repeated from the journal.
+func (s *DirStore) write(ops []op) (err error) {
+	defer err2.Handle(&err)
+
+	for _, o := range ops {
+		path := filepath.Join(s.dir, o.key+chainExt)
+		switch o.kind {
+		case opPut:
+			try.To(writeFile(path, try.To1(o.chain.TryBytes())))
+		case opDelete:
+			if err := os.Remove(path); err != nil &&
+				!errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return syncDir(s.dir)
+}
+
+// recover finishes the update of the journal if there is one.
+func (s *DirStore) recover() (err error) {
+	defer err2.Handle(&err)
+
+	journal := filepath.Join(s.dir, journalName)
+	d, err := os.ReadFile(journal)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	try.To(err)
+	// the journal is renamed in place only when it's complete
+	ops := try.To1(decodeOps(d))
+	try.To(s.write(ops))
+	try.To(os.Remove(journal))
+	return syncDir(s.dir)
+}
+
+// load reads the chain files to the index, and removes the temporary files
+// left by the crashes.
+func (s *DirStore) load() (err error) {
+	defer err2.Handle(&err)
+
+	entries := try.To1(os.ReadDir(s.dir))
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(s.dir, name)
+		switch {
+		case strings.HasSuffix(name, tmpExt):
+			try.To(os.Remove(path))
+		case strings.HasSuffix(name, chainExt):
+			c, err := chain.ParseChain(try.To1(os.ReadFile(path)))
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrCorrupt, name, err)
+			}
+			if chainKey(c)+chainExt != name {
+				return fmt.Errorf("%w: %s: wrong file name", ErrCorrupt, name)
+			}
+			s.chains[chainKey(c)] = c
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file atomically: the data is written to a temporary
+// file which is synced and renamed over the path.
+func writeFile(path string, d []byte) (err error) {
+	defer err2.Handle(&err)
+
+	tmp := path + tmpExt
+	f := try.To1(os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600))
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+	try.To1(f.Write(d))
+	try.To(f.Sync())
+	try.To(f.Close())
+	return os.Rename(tmp, path)
+}
+
+// syncDir syncs the directory entries, i.e. the renames and removes.
+func syncDir(dir string) (err error) {
+	defer err2.Handle(&err)
+
+	f := try.To1(os.Open(dir))
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+)
+
+func TestDirRecoverJournal(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	s := try.To1(OpenDir(dir))
+	try.To(s.Put(alice))
+	try.To(s.Close())
+
+	// crash after the journal was written but before the files were
+	ops := try.To1(putOps([]chain.Chain{bob, carol}))
+	try.To(writeFile(filepath.Join(dir, journalName),
+		try.To1(encodeOps(ops))))
+	// and a half-written file of an update that never committed
+	try.To(os.WriteFile(filepath.Join(dir, "x"+chainExt+tmpExt), []byte{1},
+		0o600))
+
+	s = try.To1(OpenDir(dir))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 3)
+	_, err := os.Stat(filepath.Join(dir, journalName))
+	assert.That(errors.Is(err, os.ErrNotExist))
+	_, err = os.Stat(filepath.Join(dir, "x"+chainExt+tmpExt))
+	assert.That(errors.Is(err, os.ErrNotExist))
+}
+
+func TestDirCorrupt(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	s := try.To1(OpenDir(dir))
+	try.To(s.Put(alice))
+	try.To(s.Close())
+
+	// a chain in a wrong file
+	try.To(os.WriteFile(filepath.Join(dir, chainKey(bob)+chainExt),
+		alice.Bytes(), 0o600))
+	_, err := OpenDir(dir)
+	assert.That(errors.Is(err, ErrCorrupt))
+
+	try.To(os.WriteFile(filepath.Join(dir, chainKey(bob)+chainExt),
+		[]byte("garbage"), 0o600))
+	_, err = OpenDir(dir)
+	assert.That(errors.Is(err, ErrCorrupt))
+}
+
+func TestDirFailedWrite(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	dir := t.TempDir()
+	s := try.To1(OpenDir(dir))
+	try.To(s.Put(alice))
+
+	// bob's file cannot be replaced after the journal is written
+	blocker := filepath.Join(dir, chainKey(bob)+chainExt)
+	try.To(os.MkdirAll(filepath.Join(blocker, "x"), 0o700))
+	assert.Error(s.Put(carol, bob))
+	_, err := os.Stat(filepath.Join(dir, journalName))
+	try.To(err)
+
+	// the journal of the half-done update isn't overwritten
+	assert.That(errors.Is(s.Put(carol), ErrFailed))
+	err = s.Delete(rootKey.PubKey, aliceKey.PubKey)
+	assert.That(errors.Is(err, ErrFailed))
+	try.To(s.Close())
+
+	// opening finishes the update
+	try.To(os.RemoveAll(blocker))
+	s = try.To1(OpenDir(dir))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 3)
+	_, err = os.Stat(filepath.Join(dir, journalName))
+	assert.That(errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lainio/err2"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+// recordHeaderLen is the length of the record header: payload length, its
+// CRC-32, and the CRC-32 of the both, all 4 bytes. The header has a checksum of
+// its own that a corrupted length cannot be mistaken for a torn write.
+const recordHeaderLen = 12
+
+// errTorn tells that the log ends before the record, i.e. the record is a
+// torn write.
+var errTorn = errors.New("torn record")
+
+var _ Store = (*LogStore)(nil)
+
+// LogStore is the append-only log backend. Every update is a single record in
+// the log file, and the records are replayed when the store is opened. A torn
+// record at the end of the log, i.e. an update which was written only partly
+// when we crashed, is truncated away. Use Compact to drop the history.
+type LogStore struct {
+	index
+	path string
+	f    logFile
+}
+
+// logFile is the log file, an interface for the tests.
+type logFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Truncate(size int64) error
+	Sync() error
+}
+
+// OpenLog opens the log store, and creates the log file if it doesn't exist.
+// It returns ErrCorrupt if a record is broken. Only a record which is cut short
+// by the end of the log is a torn write.
+func OpenLog(path string) (s *LogStore, err error) {
+	defer err2.Handle(&err)
+
+	f := try.To1(os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600))
+	defer func() {
+		if err != nil {
+			f.Close()
+		}
+	}()
+	s = &LogStore{index: newIndex(), path: path, f: f}
+	try.To(s.replay())
+	return s, nil
+}
+
+func (s *LogStore) Put(chains ...chain.Chain) (err error) {
+	defer err2.Handle(&err)
+
+	ops := try.To1(putOps(chains))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	try.To(s.writable())
+	try.To(s.append(ops))
+	s.apply(ops)
+	return nil
+}
+
+func (s *LogStore) Delete(root, leaf crypto.PubKey) (err error) {
+	defer err2.Handle(&err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	try.To(s.writable())
+	k := key(root, leaf)
+	if _, ok := s.chains[k]; !ok {
+		return ErrNotFound
+	}
+	ops := []op{{kind: opDelete, key: k}}
+	try.To(s.append(ops))
+	s.apply(ops)
+	return nil
+}
+
+// Compact rewrites the log with only the current chains. The new log replaces
+// the old one atomically.
+func (s *LogStore) Compact() (err error) {
+	defer err2.Handle(&err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	try.To(s.writable())
+	keys := make([]string, 0, len(s.chains))
+	for k := range s.chains {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ops := make([]op, 0, len(keys))
+	for _, k := range keys {
+		ops = append(ops, op{kind: opPut, key: k, chain: s.chains[k]})
+	}
+	var d []byte
+	if len(ops) > 0 {
+		d = record(try.To1(encodeOps(ops)))
+	}
+	try.To(writeFile(s.path, d))
+	try.To(syncDir(filepath.Dir(s.path)))
+
+	f := try.To1(os.OpenFile(s.path, os.O_RDWR, 0o600))
+	try.To1(f.Seek(0, io.SeekEnd))
+	s.f.Close()
+	s.f = f
+	return nil
+}
+
+func (s *LogStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chains == nil {
+		return nil
+	}
+	s.chains = nil
+	return s.f.Close()
+}
+
+// append writes the ops as a record to the end of the log and syncs it. If it
+// fails, the partly written record is truncated away, or the store is failed
+// if that's impossible, i.e. the next records never follow a torn one.
+func (s *LogStore) append(ops []op) (err error) {
+	defer err2.Handle(&err)
+
+	d := record(try.To1(encodeOps(ops)))
+	offset := try.To1(s.f.Seek(0, io.SeekCurrent))
+	defer err2.Handle(&err, func(err error) error {
+		if rerr := s.rollback(offset); rerr != nil {
+			s.fail(rerr)
+		}
+		return err
+	})
+	try.To1(s.f.Write(d))
+	return s.f.Sync()
+}
+
+// rollback truncates the log to the offset and continues from there.
+func (s *LogStore) rollback(offset int64) (err error) {
+	defer err2.Handle(&err)
+
+	try.To(s.f.Truncate(offset))
+	try.To1(s.f.Seek(offset, io.SeekStart))
+	return s.f.Sync()
+}
+
+// replay reads the log to the index. It leaves the file offset to the end of
+// the last valid record.
+func (s *LogStore) replay() (err error) {
+	defer err2.Handle(&err)
+
+	d := try.To1(io.ReadAll(s.f))
+	var offset int
+	for offset < len(d) {
+		payload, n, err := parseRecord(d[offset:])
+		if errors.Is(err, errTorn) {
+			// torn write at the end, the update never happened
+			try.To(s.f.Truncate(int64(offset)))
+			try.To(s.f.Sync())
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("record at %d: %w", offset, err)
+		}
+		ops, err := decodeOps(payload)
+		if err != nil {
+			return fmt.Errorf("record at %d: %w", offset, err)
+		}
+		s.apply(ops)
+		offset += n
+	}
+	try.To1(s.f.Seek(int64(offset), io.SeekStart))
+	return nil
+}
+
+// record returns the record of the payload: the header, see recordHeaderLen,
+// and the payload.
+func record(payload []byte) []byte {
+	d := make([]byte, 0, recordHeaderLen+len(payload))
+	d = binary.BigEndian.AppendUint32(d, uint32(len(payload)))
+	d = binary.BigEndian.AppendUint32(d, crc32.ChecksumIEEE(payload))
+	d = binary.BigEndian.AppendUint32(d, crc32.ChecksumIEEE(d))
+	return append(d, payload...)
+}
+
+// parseRecord returns the payload of the first record of d and the record's
+// length. It returns errTorn if d ends before the record, and ErrCorrupt if the
+// record is broken.
+func parseRecord(d []byte) (payload []byte, n int, err error) {
+	if len(d) < recordHeaderLen {
+		return nil, 0, errTorn
+	}
+	if crc32.ChecksumIEEE(d[:8]) != binary.BigEndian.Uint32(d[8:]) {
+		return nil, 0, fmt.Errorf("%w: header checksum", ErrCorrupt)
+	}
+	n = recordHeaderLen + int(binary.BigEndian.Uint32(d))
+	if len(d) < n {
+		return nil, 0, errTorn
+	}
+	payload = d[recordHeaderLen:n]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(d[4:]) {
+		return nil, 0, fmt.Errorf("%w: payload checksum", ErrCorrupt)
+	}
+	return payload, n, nil
+}
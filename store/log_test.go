@@ -0,0 +1,178 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+)
+
+func TestLogTornRecord(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	try.To(s.Put(alice))
+	try.To(s.Put(bob))
+	try.To(s.Close())
+	good := try.To1(os.Stat(path)).Size()
+
+	// crash in the middle of the third append
+	d := try.To1(os.ReadFile(path))
+	try.To(os.WriteFile(path, append(d, d[:20]...), 0o600))
+
+	s = try.To1(OpenLog(path))
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 2)
+	assert.Equal(try.To1(os.Stat(path)).Size(), good)
+
+	// the log continues from the valid end
+	try.To(s.Put(carol))
+	try.To(s.Close())
+	s = try.To1(OpenLog(path))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 3)
+}
+
+func TestLogCorrupt(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	try.To(s.Put(alice))
+	try.To(s.Put(bob))
+	try.To(s.Close())
+
+	// a broken record isn't the last one, i.e. it isn't a torn write
+	d := try.To1(os.ReadFile(path))
+	d[recordHeaderLen+1] ^= 0x01
+	try.To(os.WriteFile(path, d, 0o600))
+	_, err := OpenLog(path)
+	assert.That(errors.Is(err, ErrCorrupt))
+}
+
+func TestLogCorruptLength(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	try.To(s.Put(alice))
+	try.To(s.Put(bob))
+	try.To(s.Close())
+
+	// the length claims more than the rest of the log, but it isn't torn
+	d := try.To1(os.ReadFile(path))
+	d[0] ^= 0x80
+	try.To(os.WriteFile(path, d, 0o600))
+	_, err := OpenLog(path)
+	assert.That(errors.Is(err, ErrCorrupt))
+	assert.SLen(try.To1(os.ReadFile(path)), len(d), "nothing is truncated")
+}
+
+func TestLogCompact(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	for i := 0; i < 10; i++ {
+		try.To(s.Put(alice, bob))
+	}
+	try.To(s.Delete(rootKey.PubKey, bobKey.PubKey))
+	before := try.To1(os.Stat(path)).Size()
+	try.To(s.Compact())
+	assert.That(try.To1(os.Stat(path)).Size() < before/5)
+
+	try.To(s.Put(carol))
+	try.To(s.Close())
+	s = try.To1(OpenLog(path))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 2)
+}
+
+// failFile fails the writes after n bytes, the next failSyncs syncs, and the
+// truncates if failTrunc is set.
+type failFile struct {
+	logFile
+	n         int
+	failSyncs int
+	failTrunc bool
+}
+
+var errInjected = errors.New("injected failure")
+
+func (f *failFile) Write(d []byte) (int, error) {
+	if f.n < 0 || len(d) <= f.n {
+		return f.logFile.Write(d)
+	}
+	n, _ := f.logFile.Write(d[:f.n])
+	return n, errInjected
+}
+
+func (f *failFile) Sync() error {
+	if f.failSyncs > 0 {
+		f.failSyncs--
+		return errInjected
+	}
+	return f.logFile.Sync()
+}
+
+func (f *failFile) Truncate(size int64) error {
+	if f.failTrunc {
+		return errInjected
+	}
+	return f.logFile.Truncate(size)
+}
+
+func TestLogFailedAppend(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	try.To(s.Put(alice))
+	good := try.To1(os.Stat(path)).Size()
+
+	// partial write and failed sync are both rolled back
+	f := &failFile{logFile: s.f, n: 20}
+	s.f = f
+	assert.That(errors.Is(s.Put(bob), errInjected))
+	assert.Equal(try.To1(os.Stat(path)).Size(), good)
+	f.n, f.failSyncs = -1, 1
+	assert.That(errors.Is(s.Put(bob), errInjected))
+	assert.Equal(try.To1(os.Stat(path)).Size(), good)
+	_, err := s.Get(rootKey.PubKey, bobKey.PubKey)
+	assert.That(errors.Is(err, ErrNotFound))
+
+	// the log continues from the valid end
+	try.To(s.Put(carol))
+	try.To(s.Close())
+	s = try.To1(OpenLog(path))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 2)
+	_, err = s.Get(rootKey.PubKey, bobKey.PubKey)
+	assert.That(errors.Is(err, ErrNotFound))
+}
+
+func TestLogFailedRollback(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	path := filepath.Join(t.TempDir(), "log")
+	s := try.To1(OpenLog(path))
+	try.To(s.Put(alice))
+
+	// the torn record cannot be removed, i.e. the store stops the updates
+	s.f = &failFile{logFile: s.f, n: 20, failTrunc: true}
+	assert.That(errors.Is(s.Put(bob), errInjected))
+	assert.That(errors.Is(s.Put(carol), ErrFailed))
+	err := s.Delete(rootKey.PubKey, aliceKey.PubKey)
+	assert.That(errors.Is(err, ErrFailed))
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 1)
+	try.To(s.Close())
+
+	// opening truncates the torn record
+	s = try.To1(OpenLog(path))
+	defer s.Close()
+	assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 1)
+	try.To(s.Put(carol))
+}
@@ -0,0 +1,275 @@
+// Package store implements persistent storage for the invitation chains of the
+// nodes. Store is the interface of the backends. Chains are identified by their
+// root key and leaf key, and they can be listed by root, by leaf, i.e. the
+// member's node, and by inviter. Updates are atomic: either all of the chains
+// given to a Put are stored or none of them.
+//
+// There are two backends: DirStore keeps every chain in its own file, and
+// LogStore appends all the updates to a single log file. Both recover from the
+// crashes when they are opened. If an update fails so that it cannot be
+// undone, the store refuses the updates with ErrFailed until it's reopened.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+var (
+	ErrNotFound = errors.New("chain not found")
+	ErrInvalid  = errors.New("chain doesn't verify")
+	ErrCorrupt  = errors.New("store is corrupted")
+	ErrClosed   = errors.New("store is closed")
+	ErrFailed   = errors.New("update failed half-way, reopen the store")
+)
+
+// Store is the interface of the chain storage backends. Implementations are
+// safe for concurrent use.
+type Store interface {
+	// Put stores the chains atomically. Chains are verified before they are
+	// stored. A chain replaces the stored chain with the same root and leaf
+	// keys. If the chain ends to a key rotation, the chain of the previous
+	// leaf key is removed.
+	Put(chains ...chain.Chain) error
+
+	// Delete removes the chain. It returns ErrNotFound if there's no chain.
+	Delete(root, leaf crypto.PubKey) error
+
+	// Get returns the chain of the root and leaf keys, or ErrNotFound.
+	Get(root, leaf crypto.PubKey) (chain.Chain, error)
+
+	// ByRoot returns the chains of the web-of-trust of the root key.
+	ByRoot(root crypto.PubKey) ([]chain.Chain, error)
+
+	// ByLeaf returns the chains of the leaf key, i.e. the chains of a node.
+	ByLeaf(leaf crypto.PubKey) ([]chain.Chain, error)
+
+	// ByInviter returns the chains where the inviter invited the leaf
+	// member, see chain.Chain.InviterPubKey.
+	ByInviter(inviter crypto.PubKey) ([]chain.Chain, error)
+
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Node returns the node of the leaf key from the store. The node has no chains
+// if the key isn't found.
+func Node(s Store, leaf crypto.PubKey) (n node.Node, err error) {
+	n.Chains, err = s.ByLeaf(leaf)
+	return n, err
+}
+
+// PutNode stores all of the node's chains atomically.
+func PutNode(s Store, n node.Node) error {
+	return s.Put(n.Chains...)
+}
+
+const (
+	opPut byte = iota + 1
+	opDelete
+)
+
+// op is a single change to the store. A list of them is the atomic unit which
+// the backends persist.
+type op struct {
+	kind  byte
+	key   string
+	chain chain.Chain
+}
+
+// key returns the ID of the chain of the root and leaf keys. It's also safe to
+// be used as a file name.
+func key(root, leaf crypto.PubKey) string {
+	return hex.EncodeToString(root) + "-" + hex.EncodeToString(leaf)
+}
+
+func chainKey(c chain.Chain) string {
+	return key(c.Blocks[0].InviteePubKey, c.LeafPubKey())
+}
+
+// putOps verifies the chains and returns the ops which store them.
+func putOps(chains []chain.Chain) ([]op, error) {
+	ops := make([]op, 0, len(chains))
+	for _, c := range chains {
+		if err := c.TryVerify(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if b := c.Blocks[c.Len()-1]; b.Kind == chain.KindRotation {
+			prevLeaf := c.Blocks[c.Len()-2].InviteePubKey
+			ops = append(ops, op{
+				kind: opDelete,
+				key:  key(c.Blocks[0].InviteePubKey, prevLeaf),
+			})
+		}
+		ops = append(ops, op{kind: opPut, key: chainKey(c), chain: c})
+	}
+	return ops, nil
+}
+
+// encodeOps encodes the ops. Every op is: kind (1 byte), key length (2
+// bytes), key, data length (4 bytes) and data, which is the chain encoding for
+// the puts.
+func encodeOps(ops []op) ([]byte, error) {
+	var d []byte
+	for _, o := range ops {
+		var data []byte
+		if o.kind == opPut {
+			var err error
+			if data, err = o.chain.TryBytes(); err != nil {
+				return nil, err
+			}
+		}
+		d = append(d, o.kind)
+		d = binary.BigEndian.AppendUint16(d, uint16(len(o.key)))
+		d = append(d, o.key...)
+		d = binary.BigEndian.AppendUint32(d, uint32(len(data)))
+		d = append(d, data...)
+	}
+	return d, nil
+}
+
+func decodeOps(d []byte) ([]op, error) {
+	var ops []op
+	for len(d) > 0 {
+		if len(d) < 3 {
+			return nil, ErrCorrupt
+		}
+		o := op{kind: d[0]}
+		l := int(binary.BigEndian.Uint16(d[1:]))
+		d = d[3:]
+		if len(d) < l+4 {
+			return nil, ErrCorrupt
+		}
+		o.key = string(d[:l])
+		d = d[l:]
+		l = int(binary.BigEndian.Uint32(d))
+		d = d[4:]
+		if len(d) < l {
+			return nil, ErrCorrupt
+		}
+		switch o.kind {
+		case opPut:
+			c, err := chain.ParseChain(d[:l])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+			}
+			o.chain = c
+		case opDelete:
+		default:
+			return nil, ErrCorrupt
+		}
+		d = d[l:]
+		ops = append(ops, o)
+	}
+	return ops, nil
+}
+
+// index is the in-memory view of the store which the backends share. The
+// backends persist the ops before they apply them to the index.
+type index struct {
+	mu     sync.RWMutex
+	chains map[string]chain.Chain // nil when the store is closed
+
+	// failed is set when an update failed so that the files may be left
+	// half-updated. Updates are refused then, because only opening the
+	// store recovers the files.
+	failed error
+}
+
+func newIndex() index {
+	return index{chains: make(map[string]chain.Chain)}
+}
+
+// writable tells if the store accepts updates. Caller must hold the write
+// lock.
+func (ix *index) writable() error {
+	if ix.chains == nil {
+		return ErrClosed
+	}
+	return ix.failed
+}
+
+// fail marks the store failed because of the err, see index.failed. Caller
+// must hold the write lock.
+func (ix *index) fail(err error) {
+	ix.failed = fmt.Errorf("%w: %v", ErrFailed, err)
+}
+
+// apply applies the ops. Caller must hold the write lock.
+func (ix *index) apply(ops []op) {
+	for _, o := range ops {
+		switch o.kind {
+		case opPut:
+			ix.chains[o.key] = o.chain
+		case opDelete:
+			delete(ix.chains, o.key)
+		}
+	}
+}
+
+func (ix *index) Get(root, leaf crypto.PubKey) (chain.Chain, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if ix.chains == nil {
+		return chain.Nil, ErrClosed
+	}
+	c, ok := ix.chains[key(root, leaf)]
+	if !ok {
+		return chain.Nil, ErrNotFound
+	}
+	return c, nil
+}
+
+func (ix *index) ByRoot(root crypto.PubKey) ([]chain.Chain, error) {
+	prefix := hex.EncodeToString(root) + "-"
+	return ix.filter(func(k string, _ chain.Chain) bool {
+		return strings.HasPrefix(k, prefix)
+	})
+}
+
+func (ix *index) ByLeaf(leaf crypto.PubKey) ([]chain.Chain, error) {
+	suffix := "-" + hex.EncodeToString(leaf)
+	return ix.filter(func(k string, _ chain.Chain) bool {
+		return strings.HasSuffix(k, suffix)
+	})
+}
+
+func (ix *index) ByInviter(inviter crypto.PubKey) ([]chain.Chain, error) {
+	return ix.filter(func(_ string, c chain.Chain) bool {
+		return crypto.EqualBytes(c.InviterPubKey(), inviter)
+	})
+}
+
+// filter returns the matching chains in key order.
+func (ix *index) filter(
+	match func(string, chain.Chain) bool,
+) ([]chain.Chain, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if ix.chains == nil {
+		return nil, ErrClosed
+	}
+	keys := make([]string, 0)
+	for k, c := range ix.chains {
+		if match(k, c) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	chains := make([]chain.Chain, 0, len(keys))
+	for _, k := range keys {
+		chains = append(chains, ix.chains[k])
+	}
+	return chains, nil
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+	"github.com/lainio/ic/node"
+)
+
+var (
+	// root -> alice -> carol, root -> bob, root2 -> alice
+	rootKey, root2Key, aliceKey, bobKey, carolKey crypto.Key
+
+	root, alice, bob, carol, alice2 chain.Chain
+)
+
+func init() {
+	rootKey = crypto.NewKey()
+	root2Key = crypto.NewKey()
+	aliceKey = crypto.NewKey()
+	bobKey = crypto.NewKey()
+	carolKey = crypto.NewKey()
+
+	root = chain.NewRootChain(rootKey.PubKey)
+	alice = root.Invite(rootKey, aliceKey.PubKey, 1)
+	bob = root.Invite(rootKey, bobKey.PubKey, 1)
+	carol = alice.Invite(aliceKey, carolKey.PubKey, 2)
+	alice2 = chain.NewRootChain(root2Key.PubKey).
+		Invite(root2Key, aliceKey.PubKey, 1)
+}
+
+// backends returns the constructors of all the backends for the dir.
+func backends(dir string) map[string]func() Store {
+	return map[string]func() Store{
+		"dir": func() Store { return try.To1(OpenDir(filepath.Join(dir, "d"))) },
+		"log": func() Store { return try.To1(OpenLog(filepath.Join(dir, "l"))) },
+	}
+}
+
+func TestStore(t *testing.T) {
+	for name, open := range backends(t.TempDir()) {
+		open := open
+		t.Run(name, func(t *testing.T) {
+			defer assert.PushTester(t)()
+
+			s := open()
+			try.To(s.Put(root, alice, bob))
+			try.To(PutNode(s, node.Node{Chains: []chain.Chain{carol, alice2}}))
+
+			c := try.To1(s.Get(rootKey.PubKey, aliceKey.PubKey))
+			assert.That(chain.EqualBlocks(c.Blocks[1], alice.Blocks[1]))
+			_, err := s.Get(rootKey.PubKey, carolKey.PubKey)
+			assert.NoError(err)
+			_, err = s.Get(root2Key.PubKey, bobKey.PubKey)
+			assert.That(errors.Is(err, ErrNotFound))
+
+			assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 4)
+			assert.SLen(try.To1(s.ByRoot(root2Key.PubKey)), 1)
+			assert.SLen(try.To1(s.ByLeaf(aliceKey.PubKey)), 2)
+			assert.SLen(try.To1(s.ByInviter(rootKey.PubKey)), 2)
+			invited := try.To1(s.ByInviter(aliceKey.PubKey))
+			assert.SLen(invited, 1)
+			assert.DeepEqual(invited[0].LeafPubKey(), carolKey.PubKey)
+
+			n := try.To1(Node(s, aliceKey.PubKey))
+			assert.Equal(n.WebOfTrustInfo(node.Node{
+				Chains: []chain.Chain{carol}}).Hops, 1)
+
+			try.To(s.Delete(rootKey.PubKey, bobKey.PubKey))
+			err = s.Delete(rootKey.PubKey, bobKey.PubKey)
+			assert.That(errors.Is(err, ErrNotFound))
+
+			// survives the restart
+			try.To(s.Close())
+			_, err = s.Get(rootKey.PubKey, aliceKey.PubKey)
+			assert.That(errors.Is(err, ErrClosed))
+			s = open()
+			defer s.Close()
+			assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 3)
+			assert.SLen(try.To1(s.ByLeaf(aliceKey.PubKey)), 2)
+			_, err = s.Get(rootKey.PubKey, bobKey.PubKey)
+			assert.That(errors.Is(err, ErrNotFound))
+		})
+	}
+}
+
+func TestStoreRotation(t *testing.T) {
+	for name, open := range backends(t.TempDir()) {
+		open := open
+		t.Run(name, func(t *testing.T) {
+			defer assert.PushTester(t)()
+
+			s := open()
+			defer s.Close()
+			try.To(s.Put(root, carol))
+
+			newKey := crypto.NewKey()
+			try.To(s.Put(carol.Rotate(carolKey, newKey.PubKey)))
+			assert.SLen(try.To1(s.ByLeaf(carolKey.PubKey)), 0)
+			assert.SLen(try.To1(s.ByLeaf(newKey.PubKey)), 1)
+			assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 2)
+		})
+	}
+}
+
+func TestStoreInvalid(t *testing.T) {
+	for name, open := range backends(t.TempDir()) {
+		open := open
+		t.Run(name, func(t *testing.T) {
+			defer assert.PushTester(t)()
+
+			s := open()
+			defer s.Close()
+
+			broken := bob.Clone()
+			broken.Blocks[1].InvitersSignature[0] ^= 0x01
+			err := s.Put(alice, broken)
+			assert.That(errors.Is(err, ErrInvalid))
+			// nothing is stored
+			assert.SLen(try.To1(s.ByRoot(rootKey.PubKey)), 0)
+			assert.That(errors.Is(s.Put(chain.Nil), ErrInvalid))
+		})
+	}
+}
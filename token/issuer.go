@@ -0,0 +1,164 @@
+package token
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+// DefaultTTL is the default lifetime of the tokens.
+const DefaultTTL = 24 * time.Hour
+
+// Redemption records the invitee who redeemed the token.
+type Redemption struct {
+	InviteePubKey crypto.PubKey
+	At            time.Time
+}
+
+// Issuer issues tokens from the inviter's chain and redeems them. It keeps
+// track of the issued tokens and their redemptions in memory, i.e. the tokens
+// cannot be redeemed after the issuer is gone. Issuer is safe for concurrent
+// use.
+type Issuer struct {
+	key   crypto.KeyHandle
+	chain chain.Chain
+	now   func() time.Time
+
+	mu     sync.Mutex
+	issued map[string]*issued // key is the token ID
+}
+
+type issued struct {
+	token       Token
+	redemptions []Redemption
+}
+
+// NewIssuer returns an issuer for the chain. The key must be the chain's leaf
+// key.
+func NewIssuer(key crypto.KeyHandle, c chain.Chain) (*Issuer, error) {
+	leaf, err := c.TryLeafPubKey()
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.EqualBytes(leaf, key.PublicKey()) {
+		return nil, chain.ErrNotLeaf
+	}
+	return &Issuer{
+		key:    key,
+		chain:  c,
+		now:    time.Now,
+		issued: make(map[string]*issued),
+	}, nil
+}
+
+// Issue returns a new signed token for the position. If ttl is zero DefaultTTL
+// is used. A single-use token can be redeemed only once. It returns
+// chain.ErrPosition if the inviter cannot give the position.
+func (is *Issuer) Issue(
+	position chain.Position,
+	ttl time.Duration,
+	singleUse bool,
+) (Token, error) {
+	if !is.chain.Position().CanInvite(position) {
+		return Token{}, fmt.Errorf("%w: %v cannot invite %v",
+			chain.ErrPosition, is.chain.Position(), position)
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	t := Token{
+		ID:        crypto.RandSlice(idLen),
+		Inviter:   is.key.PublicKey(),
+		Position:  position,
+		Expires:   is.now().Add(ttl).Truncate(time.Second).UTC(),
+		SingleUse: singleUse,
+	}
+	t.Signature = is.key.Sign(t.signingInput())
+
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.prune()
+	is.issued[string(t.ID)] = &issued{token: t}
+	return t, nil
+}
+
+// Redeem checks the token and the invitee's acceptance of it, see
+// Token.Accept, and invites the inviteePubKey to the token's position. It
+// returns the invitee's new chain. It returns chain.ErrAcceptance if the
+// acceptance isn't signed by the inviteePubKey.
+func (is *Issuer) Redeem(
+	t Token,
+	inviteePubKey crypto.PubKey,
+	a chain.Acceptance,
+) (chain.Chain, error) {
+	if err := t.Verify(); err != nil {
+		return chain.Nil, err
+	}
+	if !crypto.EqualBytes(t.Inviter, is.key.PublicKey()) {
+		return chain.Nil, ErrIssuer
+	}
+
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	now := is.now()
+	rec, ok := is.issued[string(t.ID)]
+	if !ok {
+		return chain.Nil, ErrUnknown
+	}
+	// use our own copy, it's the one we signed
+	t = rec.token
+	if !crypto.VerifySign(inviteePubKey, t.acceptanceInput(), a.Signature) {
+		return chain.Nil, chain.ErrAcceptance
+	}
+	if t.Expired(now) {
+		return chain.Nil, ErrExpired
+	}
+	if t.SingleUse && len(rec.redemptions) > 0 {
+		return chain.Nil, ErrRedeemed
+	}
+	c, err := is.chain.TryInvite(is.key, inviteePubKey, t.Position)
+	if err != nil {
+		return chain.Nil, err
+	}
+	rec.redemptions = append(rec.redemptions, Redemption{
+		InviteePubKey: inviteePubKey,
+		At:            now,
+	})
+	return c, nil
+}
+
+// Redemptions returns the redemptions of the token by its ID. The second
+// return value tells if the token is known, i.e. issued and not cancelled.
+// Expired tokens are forgotten when new ones are issued.
+func (is *Issuer) Redemptions(id []byte) ([]Redemption, bool) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	rec, ok := is.issued[string(id)]
+	if !ok {
+		return nil, false
+	}
+	return append([]Redemption(nil), rec.redemptions...), true
+}
+
+// Cancel forgets the token, i.e. it cannot be redeemed after that.
+func (is *Issuer) Cancel(id []byte) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	delete(is.issued, string(id))
+}
+
+// prune removes expired tokens. Caller must hold the lock.
+func (is *Issuer) prune() {
+	now := is.now()
+	for id, rec := range is.issued {
+		if rec.token.Expired(now) {
+			delete(is.issued, id)
+		}
+	}
+}
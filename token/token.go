@@ -0,0 +1,152 @@
+// Package token implements shareable invitation tokens. The inviter issues a
+// compact, signed Token from its chain and shares it as a URL or QR code, e.g.
+// in person. The invitee accepts the token with its key, i.e. signs it as a
+// proof-of-possession of the key, and sends it back with the public key and
+// the acceptance. The inviter redeems it, i.e. calls Chain.Invite for the
+// invitee's key. Without the acceptance anyone who sees the token could use
+// it up for their own key.
+//
+// A token carries a random redemption ID, the inviter's public key, the
+// intended position of the invitee, the expiry time and the single-use flag.
+// Token signatures are domain separated, i.e. they cannot be confused with
+// block or challenge signatures.
+package token
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+const (
+	domain  = "ic-token-v1"
+	version = 0x01
+	idLen   = 16
+
+	flagSingleUse = 0x01
+)
+
+// acceptDomain separates the invitees' acceptance signatures from the token
+// signatures.
+const acceptDomain = "ic-token-accept-v1"
+
+var (
+	ErrMalformed    = errors.New("malformed token")
+	ErrBadSignature = errors.New("bad token signature")
+	ErrExpired      = errors.New("token expired")
+	ErrUnknown      = errors.New("unknown token")
+	ErrRedeemed     = errors.New("token already redeemed")
+	ErrIssuer       = errors.New("token is from other issuer")
+)
+
+// Token is the signed invitation payload.
+type Token struct {
+	ID        []byte           `json:"id"`
+	Inviter   crypto.PubKey    `json:"inviter"`
+	Position  chain.Position   `json:"position"`
+	Expires   time.Time        `json:"expires"`
+	SingleUse bool             `json:"single_use"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+// ParseToken decodes the token from the string returned by String. It's meant
+// for the data received from untrusted sources, i.e. it never panics. Note that
+// the signature isn't verified, call Verify for that.
+func ParseToken(s string) (t Token, err error) {
+	d, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, ErrMalformed
+	}
+	// version, flags, ID, position, expiry
+	const fixedLen = 1 + 1 + idLen + 8 + 8
+	if len(d) < fixedLen || d[0] != version || d[1]&^flagSingleUse != 0 {
+		return t, ErrMalformed
+	}
+	t.SingleUse = d[1]&flagSingleUse != 0
+	t.ID = append([]byte(nil), d[2:2+idLen]...)
+	d = d[2+idLen:]
+	t.Position = chain.Position(int64(binary.BigEndian.Uint64(d)))
+	t.Expires = time.Unix(int64(binary.BigEndian.Uint64(d[8:])), 0).UTC()
+	d = d[16:]
+
+	for _, f := range []*[]byte{(*[]byte)(&t.Inviter), (*[]byte)(&t.Signature)} {
+		if len(d) < 2 {
+			return Token{}, ErrMalformed
+		}
+		l := int(binary.BigEndian.Uint16(d))
+		d = d[2:]
+		if len(d) < l {
+			return Token{}, ErrMalformed
+		}
+		*f = append([]byte(nil), d[:l]...)
+		d = d[l:]
+	}
+	if len(d) != 0 || len(t.Inviter) == 0 {
+		return Token{}, ErrMalformed
+	}
+	return t, nil
+}
+
+// String returns the token as an unpadded base64url string, which can be used
+// as such in URLs and QR codes.
+func (t Token) String() string {
+	d := t.signingInput()[len(domain):]
+	d = binary.BigEndian.AppendUint16(d, uint16(len(t.Signature)))
+	d = append(d, t.Signature...)
+	return base64.RawURLEncoding.EncodeToString(d)
+}
+
+// IDString returns the redemption ID in hex for logs and tracking.
+func (t Token) IDString() string {
+	return hex.EncodeToString(t.ID)
+}
+
+// Verify checks that the token is signed by its inviter. It doesn't check the
+// expiry, see Expired.
+func (t Token) Verify() error {
+	if len(t.ID) != idLen || len(t.Inviter) == 0 {
+		return ErrMalformed
+	}
+	if !crypto.VerifySign(t.Inviter, t.signingInput(), t.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Accept is called by the invitee. It signs the token with the inviteeKey as a
+// proof-of-possession of the key, see Issuer.Redeem.
+func (t Token) Accept(inviteeKey crypto.KeyHandle) chain.Acceptance {
+	return chain.Acceptance{Signature: inviteeKey.Sign(t.acceptanceInput())}
+}
+
+// Expired tells if the token is expired at the time.
+func (t Token) Expired(at time.Time) bool {
+	return at.After(t.Expires)
+}
+
+// signingInput returns the domain string and the token encoding without the
+// signature.
+func (t Token) signingInput() []byte {
+	d := []byte(domain)
+	var flags byte
+	if t.SingleUse {
+		flags |= flagSingleUse
+	}
+	d = append(d, version, flags)
+	d = append(d, t.ID...)
+	d = binary.BigEndian.AppendUint64(d, uint64(t.Position))
+	d = binary.BigEndian.AppendUint64(d, uint64(t.Expires.Unix()))
+	d = binary.BigEndian.AppendUint16(d, uint16(len(t.Inviter)))
+	return append(d, t.Inviter...)
+}
+
+// acceptanceInput returns the bytes the invitee signs, i.e. the signing input
+// with acceptDomain instead of domain.
+func (t Token) acceptanceInput() []byte {
+	return append([]byte(acceptDomain), t.signingInput()[len(domain):]...)
+}
@@ -0,0 +1,150 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lainio/err2/assert"
+	"github.com/lainio/err2/try"
+	"github.com/lainio/ic/chain"
+	"github.com/lainio/ic/crypto"
+)
+
+var (
+	rootKey, aliceKey crypto.Key
+	aliceChain        chain.Chain
+)
+
+func init() {
+	rootKey = crypto.NewKey()
+	aliceKey = crypto.NewKey()
+
+	root := chain.NewRootChain(rootKey.PubKey)
+	aliceChain = root.Invite(rootKey, aliceKey.PubKey, chain.AdminPosition)
+}
+
+func TestTokenString(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	is := try.To1(NewIssuer(aliceKey.Handle(), aliceChain))
+	tok := try.To1(is.Issue(chain.MemberPosition, time.Hour, true))
+	assert.NoError(tok.Verify())
+	assert.Equal(len(tok.IDString()), 2*idLen)
+
+	s := tok.String()
+	tok2 := try.To1(ParseToken(s))
+	assert.NoError(tok2.Verify())
+	assert.DeepEqual(tok2, tok)
+	assert.Equal(tok2.String(), s)
+	assert.That(len(s) < 200, "fits to QR code")
+
+	// every bit is signed
+	tok2.SingleUse = false
+	assert.That(errors.Is(tok2.Verify(), ErrBadSignature))
+	tok2 = tok
+	tok2.Position = chain.AdminPosition
+	assert.That(errors.Is(tok2.Verify(), ErrBadSignature))
+}
+
+func TestParseTokenMalformed(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	is := try.To1(NewIssuer(aliceKey.Handle(), aliceChain))
+	s := try.To1(is.Issue(chain.MemberPosition, 0, false)).String()
+	for _, bad := range []string{"", "!!", s[:10], s[:len(s)-1], s + "AA"} {
+		_, err := ParseToken(bad)
+		assert.That(errors.Is(err, ErrMalformed), bad)
+	}
+}
+
+// redeem redeems the token for a new key.
+func redeem(is *Issuer, tok Token) (chain.Chain, error) {
+	k := crypto.NewKey()
+	return is.Redeem(tok, k.PubKey, tok.Accept(k))
+}
+
+func TestRedeem(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	is := try.To1(NewIssuer(aliceKey.Handle(), aliceChain))
+	tok := try.To1(is.Issue(chain.MemberPosition, time.Hour, true))
+
+	bobKey := crypto.NewKey()
+	parsed := try.To1(ParseToken(tok.String()))
+	bob := try.To1(is.Redeem(parsed, bobKey.PubKey, parsed.Accept(bobKey)))
+	assert.That(bob.Verify())
+	assert.Equal(bob.Position(), chain.MemberPosition)
+	assert.DeepEqual(bob.LeafPubKey(), bobKey.PubKey)
+
+	rs, ok := is.Redemptions(tok.ID)
+	assert.That(ok)
+	assert.SLen(rs, 1)
+	assert.DeepEqual(rs[0].InviteePubKey, bobKey.PubKey)
+
+	// single use
+	_, err := redeem(is, tok)
+	assert.That(errors.Is(err, ErrRedeemed))
+
+	// multi-use
+	tok = try.To1(is.Issue(chain.GuestPosition, time.Hour, false))
+	for i := 0; i < 3; i++ {
+		_, err = redeem(is, tok)
+		assert.NoError(err)
+	}
+	rs, _ = is.Redemptions(tok.ID)
+	assert.SLen(rs, 3)
+
+	// cancelled
+	is.Cancel(tok.ID)
+	_, err = redeem(is, tok)
+	assert.That(errors.Is(err, ErrUnknown))
+	_, ok = is.Redemptions(tok.ID)
+	assert.ThatNot(ok)
+}
+
+func TestRedeemFail(t *testing.T) {
+	defer assert.PushTester(t)()
+
+	is := try.To1(NewIssuer(aliceKey.Handle(), aliceChain))
+	tok := try.To1(is.Issue(chain.MemberPosition, time.Hour, true))
+
+	// other issuer
+	other := try.To1(NewIssuer(rootKey.Handle(),
+		chain.NewRootChain(rootKey.PubKey)))
+	_, err := redeem(other, tok)
+	assert.That(errors.Is(err, ErrIssuer))
+
+	// issued by same key but not by this issuer
+	is2 := try.To1(NewIssuer(aliceKey.Handle(), aliceChain))
+	_, err = redeem(is2, tok)
+	assert.That(errors.Is(err, ErrUnknown))
+
+	// expired
+	is.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = redeem(is, tok)
+	assert.That(errors.Is(err, ErrExpired))
+
+	// only the holder of the invitee key can redeem
+	is.now = time.Now
+	bobKey, eveKey := crypto.NewKey(), crypto.NewKey()
+	_, err = is.Redeem(tok, bobKey.PubKey, chain.Acceptance{})
+	assert.That(errors.Is(err, chain.ErrAcceptance))
+	_, err = is.Redeem(tok, bobKey.PubKey, tok.Accept(eveKey))
+	assert.That(errors.Is(err, chain.ErrAcceptance))
+	tok2 := try.To1(is.Issue(chain.MemberPosition, time.Hour, true))
+	_, err = is.Redeem(tok2, bobKey.PubKey, tok.Accept(bobKey))
+	assert.That(errors.Is(err, chain.ErrAcceptance), "other token")
+	rs, _ := is.Redemptions(tok.ID)
+	assert.SLen(rs, 0, "failed redemptions don't use the token")
+	try.To1(is.Redeem(tok, bobKey.PubKey, tok.Accept(bobKey)))
+
+	// no escalation
+	_, err = is.Issue(chain.RootPosition, 0, false)
+	assert.That(errors.Is(err, chain.ErrPosition))
+
+	_, err = NewIssuer(rootKey.Handle(), aliceChain)
+	assert.That(errors.Is(err, chain.ErrNotLeaf))
+	_, err = NewIssuer(rootKey.Handle(), chain.Nil)
+	assert.That(errors.Is(err, chain.ErrEmptyChain))
+}
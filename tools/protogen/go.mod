@@ -0,0 +1,11 @@
+module github.com/lainio/ic/tools/protogen
+
+go 1.19
+
+require (
+	github.com/bufbuild/protocompile v0.6.0
+	google.golang.org/grpc/cmd/protoc-gen-go-grpc v1.3.0
+	google.golang.org/protobuf v1.31.0
+)
+
+require golang.org/x/sync v0.3.0 // indirect
@@ -0,0 +1,10 @@
+//go:build tools
+
+package main
+
+// the protoc plugins are run from this module, i.e. their versions are the
+// ones of go.mod
+import (
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
+)